@@ -0,0 +1,61 @@
+// Command muxie-client generates a Go client package from a muxie route table.
+//
+// The route table is the JSON of the `Trie.ClientRoutes`, i.e
+//
+//	json.NewEncoder(f).Encode(mux.Routes.ClientRoutes())
+//
+// Usage:
+//
+//	muxie-client -routes routes.json -package client -o client/client.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kataras/trie-examples-to-remember-again/9/muxie"
+)
+
+func main() {
+	routesFile := flag.String("routes", "", "the JSON route table, defaults to the standard input")
+	pkg := flag.String("package", "client", "the name of the generated package")
+	output := flag.String("o", "", "the file to write the generated client, defaults to the standard output")
+	flag.Parse()
+
+	if err := run(*routesFile, *pkg, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "muxie-client: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(routesFile, pkg, output string) error {
+	var in io.Reader = os.Stdin
+	if routesFile != "" {
+		f, err := os.Open(routesFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var routes []muxie.ClientRoute
+	if err := json.NewDecoder(in).Decode(&routes); err != nil {
+		return fmt.Errorf("decode routes: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return muxie.GenerateClient(out, pkg, routes)
+}
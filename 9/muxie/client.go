@@ -0,0 +1,365 @@
+package muxie
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ClientRoute describes a named route for the client generator,
+// it can be dumped to JSON, that's the input of the `cmd/muxie-client` command.
+// See `Trie.ClientRoutes` and `GenerateClient`.
+type ClientRoute struct {
+	Name    string        `json:"name"`
+	Pattern string        `json:"pattern"`
+	Methods []string      `json:"methods,omitempty"` // empty for any method.
+	Params  []ClientParam `json:"params,omitempty"`
+}
+
+// ClientParam describes a parameter of a `ClientRoute`.
+type ClientParam struct {
+	Name string `json:"name"`
+	// "int" for parameters that are constrained to digits only, i.e :id([0-9]+) or :year(\d{4}),
+	// otherwise "string".
+	Type string `json:"type"`
+	// the minimum number of digits of an "int" parameter, i.e 4 for the :year([0-9]{4}),
+	// the generated client zero-pads the values to it.
+	Width    int  `json:"width,omitempty"`
+	Wildcard bool `json:"wildcard,omitempty"`
+}
+
+var digitsExpr = regexp.MustCompile(`^(\[0-9\]|\\d)(\+|\{([0-9]+)(,[0-9]*)?\})?$`)
+
+// digitsWidth reports whether the regexp parameter's "expr" accepts only digits, i.e "[0-9]+",
+// and its fixed (or minimum) width, if any, i.e 4 for "[0-9]{4}".
+func digitsWidth(expr string) (int, bool) {
+	m := digitsExpr.FindStringSubmatch(expr)
+	if m == nil {
+		return 0, false
+	}
+
+	width, _ := strconv.Atoi(m[3])
+	return width, true
+}
+
+// ClientRoutes returns the named routes, the nodes with a `Tag`, sorted by their names.
+func (t *Trie) ClientRoutes() (routes []ClientRoute) {
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n.end && n.Tag != "" {
+			routes = append(routes, newClientRoute(n))
+		}
+
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+
+	walk(t.root)
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Name < routes[j].Name
+	})
+	return
+}
+
+func newClientRoute(n *Node) ClientRoute {
+	route := ClientRoute{
+		Name:    n.Tag,
+		Pattern: n.key,
+		Methods: clientMethods(n.Methods()),
+	}
+
+	for _, s := range slowPathSplit(n.key) {
+		switch s[0] {
+		case ParamStart[0]:
+			param := ClientParam{Name: s[1:], Type: "string"}
+			if name, expr, ok := parseRegexpParam(s); ok {
+				param.Name = name
+				if width, ok := digitsWidth(expr); ok {
+					param.Type = "int"
+					param.Width = width
+				}
+			}
+			route.Params = append(route.Params, param)
+		case WildcardParamStart[0]:
+			route.Params = append(route.Params, ClientParam{Name: s[1:], Type: "string", Wildcard: true})
+		}
+	}
+
+	return route
+}
+
+// clientMethods removes the methods that are answered by the mux itself,
+// the OPTIONS and the HEAD of a GET.
+func clientMethods(methods []string) (list []string) {
+	for _, method := range methods {
+		if method == http.MethodOptions || (method == http.MethodHead && containsString(methods, http.MethodGet)) {
+			continue
+		}
+
+		list = append(list, method)
+	}
+
+	return
+}
+
+// GenerateClient writes the source of a Go client package, named "pkg", for the "routes".
+// The package depends only on the standard library, it contains
+// a `Client` with one method per route and a function per route which builds its path,
+// i.e for a "users.show" route of "/users/:id([0-9]+)" pattern:
+//
+//	func UsersShowPath(id int64) string
+//	func (c *Client) UsersShow(ctx context.Context, id int64, body io.Reader) (*http.Response, error)
+//
+// Routes that accept more than one (or any) HTTP method take a "method" argument as well.
+func GenerateClient(w io.Writer, pkg string, routes []ClientRoute) error {
+	var (
+		funcs                                          bytes.Buffer
+		usesStrconv, usesEscape, usesWildcard, usesPad bool
+		names                                          = make(map[string]string)
+	)
+
+	for _, route := range routes {
+		name := exportedIdent(route.Name)
+		if name == "" {
+			return fmt.Errorf("muxie: route name '%s' can't be converted to a Go identifier", route.Name)
+		}
+		if other, exists := names[name]; exists {
+			return fmt.Errorf("muxie: route names '%s' and '%s' are both converted to '%s'", other, route.Name, name)
+		}
+		names[name] = route.Name
+
+		var (
+			args  []string // i.e id int64
+			vars  []string // i.e id
+			parts []string // the path's expressions.
+		)
+
+		paramIdx := 0
+		static := ""
+		for _, s := range slowPathSplit(route.Pattern) {
+			if s == pathSep { // the root.
+				continue
+			}
+
+			static += pathSep
+			if c := s[0]; c != ParamStart[0] && c != WildcardParamStart[0] {
+				static += s
+				continue
+			}
+
+			if paramIdx >= len(route.Params) {
+				return fmt.Errorf("muxie: route '%s': missing parameters of '%s'", route.Name, route.Pattern)
+			}
+			param := route.Params[paramIdx]
+			paramIdx++
+
+			v := paramIdent(param.Name)
+			vars = append(vars, v)
+			parts = append(parts, strconv.Quote(static))
+			static = ""
+
+			switch {
+			case param.Type == "int" && param.Width > 1:
+				args = append(args, v+" int64")
+				parts = append(parts, "padInt("+v+", "+strconv.Itoa(param.Width)+")")
+				usesStrconv, usesPad = true, true
+			case param.Type == "int":
+				args = append(args, v+" int64")
+				parts = append(parts, "strconv.FormatInt("+v+", 10)")
+				usesStrconv = true
+			case param.Wildcard:
+				args = append(args, v+" string")
+				parts = append(parts, "escapeWildcard("+v+")")
+				usesWildcard = true
+			default:
+				args = append(args, v+" string")
+				parts = append(parts, "url.PathEscape("+v+")")
+				usesEscape = true
+			}
+		}
+
+		if paramIdx != len(route.Params) {
+			return fmt.Errorf("muxie: route '%s': too many parameters for '%s'", route.Name, route.Pattern)
+		}
+
+		if static != "" || len(parts) == 0 {
+			if static == "" {
+				static = pathSep
+			}
+			parts = append(parts, strconv.Quote(static))
+		}
+
+		fmt.Fprintf(&funcs, "\n// %sPath returns the path of the %q route, %s.\n", name, route.Name, route.Pattern)
+		fmt.Fprintf(&funcs, "func %sPath(%s) string {\n\treturn %s\n}\n", name, strings.Join(args, ", "), strings.Join(parts, " + "))
+
+		callArgs := append([]string{"ctx context.Context"}, args...)
+		method := ""
+		methods := "any method"
+		if len(route.Methods) == 1 {
+			method = strconv.Quote(route.Methods[0])
+			methods = route.Methods[0]
+		} else {
+			callArgs = append(callArgs[:1], append([]string{"method string"}, args...)...)
+			method = "method"
+			if len(route.Methods) > 1 {
+				methods = strings.Join(route.Methods, ", ")
+			}
+		}
+		callArgs = append(callArgs, "body io.Reader")
+
+		fmt.Fprintf(&funcs, "\n// %s calls the %q route, %s %s.\n", name, route.Name, methods, route.Pattern)
+		fmt.Fprintf(&funcs, "func (c *Client) %s(%s) (*http.Response, error) {\n\treturn c.do(ctx, %s, %sPath(%s), body)\n}\n",
+			name, strings.Join(callArgs, ", "), method, name, strings.Join(vars, ", "))
+	}
+
+	imports := []string{"context", "io", "net/http", "strings"}
+	if usesStrconv {
+		imports = append(imports, "strconv")
+	}
+	if usesEscape || usesWildcard {
+		imports = append(imports, "net/url")
+	}
+	sort.Strings(imports)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by muxie; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, path := range imports {
+		fmt.Fprintf(&b, "\t%q\n", path)
+	}
+	b.WriteString(clientSource)
+	if usesWildcard {
+		b.WriteString(clientWildcardSource)
+	}
+	if usesPad {
+		b.WriteString(clientPadSource)
+	}
+	b.Write(funcs.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+const clientSource = `)
+
+// Client sends requests to the routes of a service.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client // defaults to the http.DefaultClient.
+}
+
+// New returns a new Client for the "baseURL", i.e http://localhost:8080.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return httpClient.Do(req.WithContext(ctx))
+}
+`
+
+const clientWildcardSource = `
+// escapeWildcard escapes each segment of a wildcard parameter's value.
+func escapeWildcard(s string) string {
+	segments := strings.Split(strings.TrimPrefix(s, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+`
+
+// clientPadSource is the generated client's version of the `padDigits`.
+const clientPadSource = `
+// padInt formats the "v" zero-padded to the "width" digits of a fixed-width parameter, i.e :year([0-9]{4}).
+func padInt(v int64, width int) string {
+	s := strconv.FormatInt(v, 10)
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+
+	return s
+}
+`
+
+// padDigits zero-pads the "s" to the "width" digits of a fixed-width parameter, i.e :year([0-9]{4}).
+func padDigits(s string, width int) string {
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+
+	return s
+}
+
+// exportedIdent converts a route name, i.e "users.show" or "first/one_data", to "UsersShow" and "FirstOneData".
+func exportedIdent(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("Route")
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// paramIdent converts a parameter name to a Go identifier which does not collide
+// with the keywords and the rest of the generated method's arguments.
+func paramIdent(name string) string {
+	ident := exportedIdent(name)
+	if ident == "" {
+		return "param"
+	}
+
+	ident = string(unicode.ToLower(rune(ident[0]))) + ident[1:]
+	switch ident {
+	// the arguments of the generated methods, the imported packages and the helpers.
+	case "c", "ctx", "method", "body",
+		"context", "io", "http", "strings", "strconv", "url",
+		"escapeWildcard", "padInt":
+		return ident + "Param"
+	}
+
+	if token.IsKeyword(ident) {
+		return ident + "Param"
+	}
+
+	return ident
+}
@@ -0,0 +1,80 @@
+package muxie
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientRoutes(t *testing.T) {
+	mux := NewMux()
+	mux.HandleFunc("/users/:id([0-9]+)", func(w http.ResponseWriter, r *http.Request) {}).Name("users.show").Methods(http.MethodGet)
+	mux.HandleFunc("/users/:id([0-9]+)/files/*path", func(w http.ResponseWriter, r *http.Request) {}).Name("users.files")
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {}).Name("index").Methods(http.MethodGet, http.MethodPost)
+	mux.HandleFunc("/unnamed/:name", func(w http.ResponseWriter, r *http.Request) {})
+
+	expected := []ClientRoute{
+		{Name: "index", Pattern: "/", Methods: []string{http.MethodGet, http.MethodPost}},
+		{Name: "users.files", Pattern: "/users/:id([0-9]+)/files/*path", Params: []ClientParam{
+			{Name: "id", Type: "int"},
+			{Name: "path", Type: "string", Wildcard: true},
+		}},
+		{Name: "users.show", Pattern: "/users/:id([0-9]+)", Methods: []string{http.MethodGet}, Params: []ClientParam{
+			{Name: "id", Type: "int"},
+		}},
+	}
+
+	if got := mux.Routes.ClientRoutes(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected client routes:\n%#v\nbut got:\n%#v", expected, got)
+	}
+
+	// parameters named as the imported packages and fixed-width digits.
+	mux.HandleFunc("/links/:url", func(w http.ResponseWriter, r *http.Request) {}).Name("links")
+	mux.HandleFunc("/archive/:year([0-9]{4})", func(w http.ResponseWriter, r *http.Request) {}).Name("archive").Methods(http.MethodGet)
+
+	var b bytes.Buffer
+	if err := GenerateClient(&b, "client", mux.Routes.ClientRoutes()); err != nil {
+		t.Fatal(err)
+	}
+	src := b.String()
+
+	// the generated package should compile.
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err = conf.Check("client", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("generated client does not compile: %v\n%s", err, src)
+	}
+
+	for _, expected := range []string{
+		"package client\n",
+		`func IndexPath() string {` + "\n\treturn \"/\"\n}",
+		`func (c *Client) Index(ctx context.Context, method string, body io.Reader) (*http.Response, error) {`,
+		`return "/users/" + strconv.FormatInt(id, 10)`,
+		`func (c *Client) UsersShow(ctx context.Context, id int64, body io.Reader) (*http.Response, error) {` +
+			"\n\treturn c.do(ctx, \"GET\", UsersShowPath(id), body)",
+		`return "/users/" + strconv.FormatInt(id, 10) + "/files/" + escapeWildcard(path)`,
+		`func (c *Client) UsersFiles(ctx context.Context, method string, id int64, path string, body io.Reader) (*http.Response, error) {`,
+		`return "/links/" + url.PathEscape(urlParam)`,
+		`return "/archive/" + padInt(year, 4)`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Fatalf("expected generated client to contain:\n%s\nbut got:\n%s", expected, src)
+		}
+	}
+
+	err = GenerateClient(&b, "client", []ClientRoute{{Name: "a.b", Pattern: "/a"}, {Name: "a_b", Pattern: "/b"}})
+	if err == nil {
+		t.Fatalf("expected an error for route names that are converted to the same method")
+	}
+}
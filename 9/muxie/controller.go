@@ -0,0 +1,289 @@
+package muxie
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var controllerMethods = map[string]string{
+	"Get":     http.MethodGet,
+	"Post":    http.MethodPost,
+	"Put":     http.MethodPut,
+	"Patch":   http.MethodPatch,
+	"Delete":  http.MethodDelete,
+	"Head":    http.MethodHead,
+	"Options": http.MethodOptions,
+	"Connect": http.MethodConnect,
+	"Trace":   http.MethodTrace,
+}
+
+var (
+	responseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+	requestType        = reflect.TypeOf((*http.Request)(nil))
+	contextType        = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Controller registers the exported methods of "ctrl" as routes under the "prefix",
+// based on their names: the first word is the HTTP method, the next words are path segments
+// and each "By" word is a named parameter, i.e
+//
+//	Get()                   GET    /prefix
+//	GetBy(id int)           GET    /prefix/:id
+//	PostItems()             POST   /prefix/items
+//	GetItemsBy(id int64)    GET    /prefix/items/:id
+//	DeleteItemsByTagsBy(id int, tag string)  DELETE /prefix/items/:id/tags/:id2
+//
+// The named parameters are "id", "id2", "id3" and so on, they are converted to the method's
+// typed arguments (string, bool, ints, uints and floats) in order, a conversion failure is answered with 404.
+// The method can accept an `http.ResponseWriter`, a `*http.Request` and a `context.Context`, at any position,
+// and it can return nothing, an error, a value (which is written as JSON) or a value and an error.
+// A returned error is passed to the group's `ErrorResponder`, see `RespondError`.
+//
+// It returns the names of the exported methods that could not be mapped to a route.
+func (m *Mux) Controller(prefix string, ctrl interface{}) (unmapped []string) {
+	v := reflect.ValueOf(ctrl)
+	typ := v.Type()
+
+	prefix = strings.Trim(prefix, pathSep)
+	if prefix != "" {
+		prefix = pathSep + prefix
+	}
+
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		httpMethod, pattern, ok := parseControllerMethodName(method.Name)
+		if !ok {
+			unmapped = append(unmapped, method.Name)
+			continue
+		}
+
+		handler, ok := newControllerHandler(v.Method(i), strings.Count(pattern, ParamStart))
+		if !ok {
+			unmapped = append(unmapped, method.Name)
+			continue
+		}
+
+		m.Handle(prefix+pattern, handler).Methods(httpMethod)
+	}
+
+	sort.Strings(unmapped)
+	return
+}
+
+// parseControllerMethodName parses a method name, i.e "GetItemsBy" to "GET" and "/items/:id".
+func parseControllerMethodName(name string) (httpMethod, pattern string, ok bool) {
+	words := splitCamelCase(name)
+	if len(words) == 0 {
+		return
+	}
+
+	httpMethod, ok = controllerMethods[words[0]]
+	if !ok {
+		return
+	}
+
+	var b strings.Builder
+	params := 0
+	for _, word := range words[1:] {
+		b.WriteByte(pathSepB)
+		if word == "By" {
+			params++
+			b.WriteString(ParamStart + "id")
+			if params > 1 {
+				b.WriteString(strconv.Itoa(params))
+			}
+			continue
+		}
+
+		b.WriteString(strings.ToLower(word))
+	}
+
+	pattern = b.String()
+	if pattern == "" {
+		pattern = pathSep
+	}
+
+	return
+}
+
+// splitCamelCase splits a name to its words, i.e "GetHTTPStatusBy" to "Get", "HTTP", "Status", "By".
+func splitCamelCase(name string) (words []string) {
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return
+}
+
+type controllerArg int
+
+const (
+	argResponseWriter controllerArg = iota
+	argRequest
+	argContext
+	argParam
+)
+
+// newControllerHandler returns a handler which calls the "fn" method, it reports false if
+// the method's signature is not supported or its typed arguments are not equal to the "params".
+func newControllerHandler(fn reflect.Value, params int) (http.Handler, bool) {
+	typ := fn.Type()
+
+	args := make([]controllerArg, typ.NumIn())
+	var paramTypes []reflect.Type
+	for i := range args {
+		in := typ.In(i)
+		switch {
+		case in == responseWriterType:
+			args[i] = argResponseWriter
+		case in == requestType:
+			args[i] = argRequest
+		case in == contextType:
+			args[i] = argContext
+		case isParamKind(in.Kind()):
+			args[i] = argParam
+			paramTypes = append(paramTypes, in)
+		default:
+			return nil, false
+		}
+	}
+
+	if len(paramTypes) != params {
+		return nil, false
+	}
+
+	hasValue, hasError := false, false
+	switch typ.NumOut() {
+	case 0:
+	case 1:
+		hasError = typ.Out(0) == errorType
+		hasValue = !hasError
+	case 2:
+		if typ.Out(1) != errorType {
+			return nil, false
+		}
+		hasValue, hasError = true, true
+	default:
+		return nil, false
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := make([]reflect.Value, len(args))
+		paramIdx := 0
+		for i, arg := range args {
+			switch arg {
+			case argResponseWriter:
+				in[i] = reflect.ValueOf(w)
+			case argRequest:
+				in[i] = reflect.ValueOf(r)
+			case argContext:
+				in[i] = reflect.ValueOf(r.Context())
+			case argParam:
+				key := "id"
+				if paramIdx > 0 {
+					key += strconv.Itoa(paramIdx + 1)
+				}
+
+				value, err := convertParam(GetParam(w, key), paramTypes[paramIdx])
+				if err != nil {
+					Error(w, r, http.StatusNotFound)
+					return
+				}
+
+				in[i] = value
+				paramIdx++
+			}
+		}
+
+		out := fn.Call(in)
+
+		if hasError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				RespondError(w, r, err)
+				return
+			}
+		}
+
+		if hasValue {
+			if value := out[0]; isNilValue(value) {
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			json.NewEncoder(w).Encode(out[0].Interface())
+		}
+	}), true
+}
+
+func isParamKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// convertParam converts a parameter's value to the "typ", which its kind is one of the `isParamKind`.
+func convertParam(s string, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	}
+
+	return v, nil
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
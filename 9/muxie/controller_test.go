@@ -0,0 +1,91 @@
+package muxie
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type itemsController struct {
+	items map[int]string
+}
+
+func (c *itemsController) Get(w http.ResponseWriter) {
+	fmt.Fprintf(w, "%d items", len(c.items))
+}
+
+func (c *itemsController) GetBy(id int) (map[string]interface{}, error) {
+	name, ok := c.items[id]
+	if !ok {
+		return nil, fmt.Errorf("item %d: %w", id, ErrNotFound)
+	}
+
+	return map[string]interface{}{"id": id, "name": name}, nil
+}
+
+func (c *itemsController) PostItems(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, "created")
+}
+
+func (c *itemsController) DeleteItemsByTagsBy(ctx context.Context, id int64, tag string, w http.ResponseWriter) error {
+	if tag == "locked" {
+		return errors.New("locked tag")
+	}
+
+	fmt.Fprintf(w, "deleted tag %s of %d", tag, id)
+	return nil
+}
+
+func (c *itemsController) GetHTTPStatus() string {
+	return "ok"
+}
+
+// unmapped.
+func (c *itemsController) Helper() {}
+
+func (c *itemsController) GetWrongBy() {}
+
+func (c *itemsController) PutBy(id chan int) {}
+
+func TestMuxController(t *testing.T) {
+	mux := NewMux()
+	unmapped := mux.Controller("/items", &itemsController{items: map[int]string{1: "first"}})
+
+	if expected, got := []string{"GetWrongBy", "Helper", "PutBy"}, unmapped; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected unmapped methods: %v but got %v", expected, got)
+	}
+
+	tests := []struct {
+		method     string
+		path       string
+		statusCode int
+		body       string
+	}{
+		{http.MethodGet, "/items", http.StatusOK, "1 items"},
+		{http.MethodGet, "/items/1", http.StatusOK, `{"id":1,"name":"first"}` + "\n"},
+		{http.MethodGet, "/items/2", http.StatusNotFound, "404 page not found\n"},
+		{http.MethodGet, "/items/notanumber", http.StatusNotFound, "404 page not found\n"},
+		{http.MethodPost, "/items/items", http.StatusOK, "created"},
+		{http.MethodDelete, "/items/items/42/tags/red", http.StatusOK, "deleted tag red of 42"},
+		{http.MethodDelete, "/items/items/42/tags/locked", http.StatusInternalServerError, "Internal Server Error\n"},
+		{http.MethodGet, "/items/http/status", http.StatusOK, `"ok"` + "\n"},
+		{http.MethodPost, "/items", http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s %s: expected status code: %d but got %d", i, tt.method, tt.path, expected, got)
+		}
+
+		if expected, got := tt.body, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s %s: expected to receive '%s' but got '%s'", i, tt.method, tt.path, expected, got)
+		}
+	}
+}
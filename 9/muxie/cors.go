@@ -0,0 +1,166 @@
+package muxie
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS holds the Cross-Origin Resource Sharing settings of a `Mux` or a group of it,
+// see `Mux#CORS` and `SubMux#SetCORS`.
+//
+// Preflight requests are answered by the `Mux` itself and only for the paths that a route exists for,
+// so the handlers don't have to handle them. Unknown paths are answered by the not found handler
+// and disallowed origins, methods or headers with 403 Forbidden.
+type CORS struct {
+	// AllowedOrigins are the origins that can make cross-origin requests,
+	// they can be exact (i.e "https://example.com"), "*" for any origin
+	// or contain a single wildcard, i.e "https://*.example.com".
+	AllowedOrigins []string
+	// AllowOriginFunc, if not nil, is checked when the origin is not one of the `AllowedOrigins`.
+	AllowOriginFunc func(origin string, r *http.Request) bool
+	// AllowedMethods are the methods that cross-origin requests can use,
+	// if empty then the route's registered methods (see `MethodHandler`) are allowed,
+	// if no registered methods then the simple methods (GET, HEAD and POST).
+	AllowedMethods []string
+	// AllowedHeaders are the non-simple headers that cross-origin requests can use,
+	// "*" allows any header, if empty then only the simple headers are allowed.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers that the browser can expose to the client.
+	ExposedHeaders []string
+	// AllowCredentials allows requests with credentials, i.e cookies.
+	AllowCredentials bool
+	// MaxAge is how long the results of a preflight request can be cached, zero means no header.
+	MaxAge time.Duration
+}
+
+var simpleMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+func (c *CORS) originAllowed(origin string, r *http.Request) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+
+		if idx := strings.IndexByte(allowed, '*'); idx != -1 {
+			prefix, suffix := allowed[:idx], allowed[idx+1:]
+			if len(origin) >= len(prefix)+len(suffix) &&
+				strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+
+	if c.AllowOriginFunc != nil {
+		return c.AllowOriginFunc(origin, r)
+	}
+
+	return false
+}
+
+func (c *CORS) anyOrigin() bool {
+	return !c.AllowCredentials && containsString(c.AllowedOrigins, "*")
+}
+
+func (c *CORS) allowedMethods(n *Node) []string {
+	if len(c.AllowedMethods) > 0 {
+		return c.AllowedMethods
+	}
+
+	if methods := n.Methods(); len(methods) > 0 {
+		return methods
+	}
+
+	return simpleMethods
+}
+
+func (c *CORS) headersAllowed(requested string) bool {
+	if requested == "" || containsString(c.AllowedHeaders, "*") {
+		return true
+	}
+
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+
+		allowed := false
+		for _, h := range c.AllowedHeaders {
+			if strings.EqualFold(h, header) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *CORS) setOrigin(w http.ResponseWriter, origin string) {
+	h := w.Header()
+	if c.anyOrigin() {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if c.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// handle sets the CORS headers of a cross-origin request to the route's node "n"
+// and it reports whether the request was a preflight one and it's already answered.
+func (c *CORS) handle(w http.ResponseWriter, r *http.Request, n *Node) bool {
+	// the response depends on the origin even if there is none or it's not allowed,
+	// so caches must not reuse it for other origins.
+	w.Header().Add("Vary", "Origin")
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+
+	requestedMethod := r.Header.Get("Access-Control-Request-Method")
+	if r.Method != http.MethodOptions || requestedMethod == "" {
+		// actual request.
+		if c.originAllowed(origin, r) {
+			c.setOrigin(w, origin)
+			if len(c.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+			}
+		}
+
+		return false
+	}
+
+	// preflight request.
+	methods := c.allowedMethods(n)
+	requestedHeaders := r.Header.Get("Access-Control-Request-Headers")
+	if !c.originAllowed(origin, r) || !containsString(methods, strings.ToUpper(requestedMethod)) || !c.headersAllowed(requestedHeaders) {
+		Error(w, r, http.StatusForbidden)
+		return true
+	}
+
+	c.setOrigin(w, origin)
+	h := w.Header()
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if requestedHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", requestedHeaders)
+	}
+	if c.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
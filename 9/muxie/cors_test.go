@@ -0,0 +1,109 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMuxCORS(t *testing.T) {
+	mux := NewMux()
+	mux.HandleFunc("/public", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "public")
+	})
+
+	api := mux.Of("/api")
+	api.SetCORS(&CORS{
+		AllowedOrigins: []string{"https://example.com", "https://*.acme.com"},
+		AllowOriginFunc: func(origin string, r *http.Request) bool {
+			return origin == "https://trusted.org"
+		},
+		AllowedHeaders:   []string{"Content-Type", "X-Token"},
+		ExposedHeaders:   []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	api.Handle("/users", Methods().
+		HandleFunc(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "users")
+		}).
+		HandleFunc(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "created")
+		}))
+
+	public := mux.Of("/open")
+	public.SetCORS(&CORS{AllowedOrigins: []string{"*"}})
+	public.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data")
+	})
+
+	tests := []struct {
+		method     string
+		path       string
+		header     http.Header
+		statusCode int
+		expected   http.Header
+	}{
+		// preflight.
+		{http.MethodOptions, "/api/users", http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"POST"}, "Access-Control-Request-Headers": {"content-type, x-token"}},
+			http.StatusNoContent, http.Header{
+				"Access-Control-Allow-Origin":      {"https://example.com"},
+				"Access-Control-Allow-Methods":     {"GET, HEAD, OPTIONS, POST"},
+				"Access-Control-Allow-Headers":     {"content-type, x-token"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Max-Age":           {"600"},
+			}},
+		{http.MethodOptions, "/api/users", http.Header{"Origin": {"https://app.acme.com"}, "Access-Control-Request-Method": {"GET"}},
+			http.StatusNoContent, http.Header{"Access-Control-Allow-Origin": {"https://app.acme.com"}}},
+		{http.MethodOptions, "/api/users", http.Header{"Origin": {"https://trusted.org"}, "Access-Control-Request-Method": {"GET"}},
+			http.StatusNoContent, http.Header{"Access-Control-Allow-Origin": {"https://trusted.org"}}},
+		// not registered method.
+		{http.MethodOptions, "/api/users", http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"DELETE"}},
+			http.StatusForbidden, http.Header{"Access-Control-Allow-Origin": nil}},
+		// not allowed header.
+		{http.MethodOptions, "/api/users", http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"GET"}, "Access-Control-Request-Headers": {"X-Other"}},
+			http.StatusForbidden, http.Header{"Access-Control-Allow-Origin": nil}},
+		// not allowed origin.
+		{http.MethodOptions, "/api/users", http.Header{"Origin": {"https://evil.com"}, "Access-Control-Request-Method": {"GET"}},
+			http.StatusForbidden, http.Header{"Access-Control-Allow-Origin": nil}},
+		// route does not exist.
+		{http.MethodOptions, "/api/other", http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"GET"}},
+			http.StatusNotFound, http.Header{"Access-Control-Allow-Origin": nil}},
+		// actual requests.
+		{http.MethodGet, "/api/users", http.Header{"Origin": {"https://example.com"}},
+			http.StatusOK, http.Header{"Access-Control-Allow-Origin": {"https://example.com"}, "Access-Control-Expose-Headers": {"X-Total"}, "Vary": {"Origin"}}},
+		{http.MethodGet, "/api/users", http.Header{"Origin": {"https://evil.com"}},
+			http.StatusOK, http.Header{"Access-Control-Allow-Origin": nil, "Vary": {"Origin"}}},
+		{http.MethodGet, "/api/users", http.Header{},
+			http.StatusOK, http.Header{"Access-Control-Allow-Origin": nil, "Vary": {"Origin"}}},
+		{http.MethodGet, "/open/data", http.Header{"Origin": {"https://any.com"}},
+			http.StatusOK, http.Header{"Access-Control-Allow-Origin": {"*"}}},
+		// group without CORS.
+		{http.MethodGet, "/public", http.Header{"Origin": {"https://example.com"}},
+			http.StatusOK, http.Header{"Access-Control-Allow-Origin": nil, "Vary": nil}},
+	}
+
+	for i, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header = tt.header
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s %s: expected status code: %d but got %d", i, tt.method, tt.path, expected, got)
+		}
+
+		for key, values := range tt.expected {
+			var expected string
+			if len(values) > 0 {
+				expected = values[0]
+			}
+
+			if got := rec.Header().Get(key); expected != got {
+				t.Fatalf("[%d] %s %s: expected header '%s' to be '%s' but got '%s'", i, tt.method, tt.path, key, expected, got)
+			}
+		}
+	}
+}
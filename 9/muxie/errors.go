@@ -0,0 +1,120 @@
+package muxie
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// ErrorHandler renders the response of an error status code, i.e a branded 404 page, see `Mux#HandleError`.
+type ErrorHandler interface {
+	ServeError(w http.ResponseWriter, r *http.Request, statusCode int)
+}
+
+// ErrorHandlerFunc is the func adapter of an `ErrorHandler`.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, statusCode int)
+
+func (fn ErrorHandlerFunc) ServeError(w http.ResponseWriter, r *http.Request, statusCode int) {
+	fn(w, r, statusCode)
+}
+
+// DefaultErrorHandler renders the status codes that no error handler is registered for,
+// it responds with the status text as plain text, 404 responds like the net/http does.
+var DefaultErrorHandler ErrorHandler = ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request, statusCode int) {
+	if statusCode == http.StatusNotFound {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.Error(w, http.StatusText(statusCode), statusCode)
+})
+
+// AnyStatus can be passed to the `HandleError` to register an error handler for any status code.
+const AnyStatus = 0
+
+// HandleError registers a "handler" to render the "statusCode" responses of this group (or `Mux`),
+// i.e JSON problem details under "/api" and branded HTML pages elsewhere.
+// The "statusCode" can be `AnyStatus` to render any error status code that has no specific handler registered.
+//
+// The mux itself uses them for 404 (not found), 405 (method not allowed), 500 (recovered panics)
+// and 503 (maintenance) responses, between others, and handlers through the `Error` helper.
+// The closest group's handlers have priority over its parent's ones,
+// the `NotFoundHandler` and `PanicHandler`, if set, have priority over the 404 and 500 handlers of the same group.
+func (m *Mux) HandleError(statusCode int, handler ErrorHandler) {
+	if m.errorHandlers == nil {
+		m.errorHandlers = make(map[int]ErrorHandler)
+	}
+
+	m.errorHandlers[statusCode] = handler
+}
+
+// HandleErrorFunc same as `HandleError` but it accepts a func.
+func (m *Mux) HandleErrorFunc(statusCode int, handlerFunc func(http.ResponseWriter, *http.Request, int)) {
+	m.HandleError(statusCode, ErrorHandlerFunc(handlerFunc))
+}
+
+// Error responds with the error handler of the "statusCode" of the group that serves the request,
+// it falls back to the `DefaultErrorHandler` if the "w" is not the one that the `Mux` passed to the handler.
+func Error(w http.ResponseWriter, r *http.Request, statusCode int) {
+	if pw, ok := w.(*paramsWriter); ok && pw.group != nil {
+		pw.group.serveError(pw, r, statusCode)
+		return
+	}
+
+	DefaultErrorHandler.ServeError(w, r, statusCode)
+}
+
+func (m *Mux) serveError(w http.ResponseWriter, r *http.Request, statusCode int) {
+	m.serveStatus(w, r, statusCode, false)
+}
+
+// serveStatus responds with the closest group's handler of the "statusCode",
+// if "recovered" then it's the response of a recovered panic and the `PanicHandler` is considered too,
+// i.e a group's HandleError(500, ...) wins over the root's PanicHandler.
+func (m *Mux) serveStatus(w http.ResponseWriter, r *http.Request, statusCode int, recovered bool) {
+	for g := m; g != nil; g = g.parent {
+		if recovered && g.PanicHandler != nil {
+			g.PanicHandler.ServeHTTP(w, r)
+			return
+		}
+
+		if statusCode == http.StatusNotFound && g.NotFoundHandler != nil {
+			g.NotFoundHandler.ServeHTTP(w, r)
+			return
+		}
+
+		if h, ok := g.errorHandlers[statusCode]; ok {
+			h.ServeError(w, r, statusCode)
+			return
+		}
+
+		if h, ok := g.errorHandlers[AnyStatus]; ok {
+			h.ServeError(w, r, statusCode)
+			return
+		}
+	}
+
+	DefaultErrorHandler.ServeError(w, r, statusCode)
+}
+
+// SetMaintenance enables or disables the maintenance mode of this group (and its children),
+// while enabled all of its requests are answered with the 503 Service Unavailable error handler.
+// It is safe to be called while serving.
+func (m *Mux) SetMaintenance(enable bool) {
+	var v int32
+	if enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&m.maintenance, v)
+}
+
+// Maintenance reports whether this group or one of its parents is in maintenance mode.
+func (m *Mux) Maintenance() bool {
+	for g := m; g != nil; g = g.parent {
+		if atomic.LoadInt32(&g.maintenance) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMuxHandleError(t *testing.T) {
+	mux := NewMux()
+	mux.Recover = true
+	mux.HandleErrorFunc(http.StatusNotFound, func(w http.ResponseWriter, r *http.Request, statusCode int) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(statusCode)
+		fmt.Fprintf(w, "<h1>%d page not found</h1>", statusCode)
+	})
+	mux.HandleFunc("/conflict", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, http.StatusConflict)
+	})
+	mux.PanicHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "root panic")
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("root panic")
+	})
+
+	api := mux.Of("/api")
+	api.HandleErrorFunc(AnyStatus, func(w http.ResponseWriter, r *http.Request, statusCode int) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(statusCode)
+		fmt.Fprintf(w, `{"status":%d,"title":"%s"}`, statusCode, http.StatusText(statusCode))
+	})
+	api.Handle("/users", Methods().HandleFunc(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "users")
+	}))
+	api.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("api panic")
+	})
+	api.HandleFunc("/teapot", func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, http.StatusTeapot)
+	})
+
+	admin := api.Of("/admin")
+	admin.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "stats")
+	})
+
+	tests := []struct {
+		method      string
+		path        string
+		statusCode  int
+		contentType string
+		body        string
+	}{
+		{http.MethodGet, "/other", http.StatusNotFound, "text/html", "<h1>404 page not found</h1>"},
+		{http.MethodGet, "/conflict", http.StatusConflict, "text/plain; charset=utf-8", "Conflict\n"},
+		{http.MethodGet, "/panic", http.StatusInternalServerError, "text/plain", "root panic"},
+		{http.MethodGet, "/api/other", http.StatusNotFound, "application/problem+json", `{"status":404,"title":"Not Found"}`},
+		{http.MethodPost, "/api/users", http.StatusMethodNotAllowed, "application/problem+json", `{"status":405,"title":"Method Not Allowed"}`},
+		// the closest group's error handler wins over the root's panic handler.
+		{http.MethodGet, "/api/panic", http.StatusInternalServerError, "application/problem+json", `{"status":500,"title":"Internal Server Error"}`},
+		{http.MethodGet, "/api/teapot", http.StatusTeapot, "application/problem+json", `{"status":418,"title":"I'm a teapot"}`},
+		// inherited.
+		{http.MethodGet, "/api/admin/other", http.StatusNotFound, "application/problem+json", `{"status":404,"title":"Not Found"}`},
+		{http.MethodGet, "/api/admin/stats", http.StatusOK, "text/plain; charset=utf-8", "stats"},
+	}
+
+	serve := func() {
+		for i, tt := range tests {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if expected, got := tt.statusCode, rec.Code; expected != got {
+				t.Fatalf("[%d] %s %s: expected status code: %d but got %d", i, tt.method, tt.path, expected, got)
+			}
+
+			if expected, got := tt.contentType, rec.Header().Get("Content-Type"); expected != got {
+				t.Fatalf("[%d] %s %s: expected content type: '%s' but got '%s'", i, tt.method, tt.path, expected, got)
+			}
+
+			if expected, got := tt.body, rec.Body.String(); expected != got {
+				t.Fatalf("[%d] %s %s: expected to receive '%s' but got '%s'", i, tt.method, tt.path, expected, got)
+			}
+		}
+	}
+
+	serve()
+
+	admin.SetMaintenance(true)
+	if !admin.Maintenance() || api.Maintenance() {
+		t.Fatalf("expected only the admin group to be in maintenance mode")
+	}
+
+	tests = tests[len(tests)-1:]
+	tests[0].statusCode = http.StatusServiceUnavailable
+	tests[0].contentType = "application/problem+json"
+	tests[0].body = `{"status":503,"title":"Service Unavailable"}`
+	serve()
+
+	admin.SetMaintenance(false)
+	tests[0].statusCode = http.StatusOK
+	tests[0].contentType = "text/plain; charset=utf-8"
+	tests[0].body = "stats"
+	serve()
+}
@@ -0,0 +1,110 @@
+package muxie
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExplainBranch is the decision that the trie took for a path segment, see `Trie#Explain`.
+type ExplainBranch string
+
+const (
+	// BranchStatic means that the segment matched a static child.
+	BranchStatic ExplainBranch = "static"
+	// BranchRegexp means that the segment matched a regexp constrained named parameter child, i.e ":year([0-9]{4})".
+	BranchRegexp ExplainBranch = "regexp"
+	// BranchParam means that the segment matched a named parameter child, i.e ":id".
+	BranchParam ExplainBranch = "param"
+	// BranchWildcard means that the rest of the path matched a wildcard child, i.e "*path".
+	BranchWildcard ExplainBranch = "wildcard"
+	// BranchMidWildcard means that one or more segments matched a mid-pattern wildcard child, i.e "*path/edit".
+	BranchMidWildcard ExplainBranch = "mid-wildcard"
+	// BranchBacktrack means that the search was retried with backtracking, it happens
+	// only when there are mid-pattern wildcards or regexp constrained parameters registered.
+	BranchBacktrack ExplainBranch = "backtrack"
+	// BranchClosestWildcard means that the search fall back to the closest parent's wildcard.
+	BranchClosestWildcard ExplainBranch = "closest-wildcard"
+	// BranchRootWildcard means that the search fall back to the root wildcard, i.e "/*path".
+	BranchRootWildcard ExplainBranch = "root-wildcard"
+	// BranchNotFound means that nothing matched.
+	BranchNotFound ExplainBranch = "not-found"
+)
+
+// ExplainStep is a single decision of the trie's search.
+type ExplainStep struct {
+	// Segment is the path segment, or the rest of the path, that the decision was made for.
+	Segment string
+	// Candidates are the children keys of the node that the decision was made on.
+	Candidates []string
+	// Branch is the decision.
+	Branch ExplainBranch
+	// Reason is why a fallback branch was taken, empty for static matches.
+	Reason string
+}
+
+// Explanation is the step-by-step trace of a trie's search, see `Trie#Explain`.
+type Explanation struct {
+	Path   string
+	Steps  []ExplainStep
+	Node   *Node // the final node, nil if not found.
+	Params []ParamEntry
+}
+
+func (e *Explanation) step(segment string, n *Node, branch ExplainBranch, reason string) {
+	var candidates []string
+	if n != nil {
+		for key := range n.children {
+			candidates = append(candidates, key)
+		}
+		sort.Strings(candidates)
+	}
+
+	e.Steps = append(e.Steps, ExplainStep{
+		Segment:    segment,
+		Candidates: candidates,
+		Branch:     branch,
+		Reason:     reason,
+	})
+}
+
+// Explain runs the same search as `Search` does for the "path"
+// but it returns the sequence of decisions that were taken in order to find (or not) a route,
+// useful to diagnose surprising matches.
+func (t *Trie) Explain(path string) *Explanation {
+	e := &Explanation{Path: path}
+	e.Node = t.search(path, Setter(func(key, value string) {
+		e.Params = append(e.Params, ParamEntry{Key: key, Value: value})
+	}), e)
+
+	return e
+}
+
+// String returns a human-readable, multi-line, form of the explanation, i.e for bug reports.
+func (e *Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "path: %s\n", e.Path)
+	for i, step := range e.Steps {
+		fmt.Fprintf(&b, "%d. %-16s segment: %q candidates: [%s]", i+1, step.Branch, step.Segment, strings.Join(step.Candidates, " "))
+		if step.Reason != "" {
+			fmt.Fprintf(&b, " (%s)", step.Reason)
+		}
+		b.WriteByte('\n')
+	}
+
+	if e.Node == nil {
+		b.WriteString("result: not found\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "result: %s", e.Node.key)
+	if e.Node.Tag != "" {
+		fmt.Fprintf(&b, " (%s)", e.Node.Tag)
+	}
+	b.WriteByte('\n')
+	for _, p := range e.Params {
+		fmt.Fprintf(&b, "  %s = %q\n", p.Key, p.Value)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package muxie
+
+import (
+	"testing"
+)
+
+func TestTrieExplain(t *testing.T) {
+	tree := NewTrie()
+	tree.InsertRoute("/hello/*p", "wildcard", nil)
+	tree.InsertRoute("/hello/:p1/static/:p2", "static_between_params", nil)
+	tree.InsertRoute("/users/:id", "user", nil)
+	tree.InsertRoute("/users/me", "me", nil)
+
+	tests := []struct {
+		path     string
+		key      string
+		branches []ExplainBranch
+		params   []ParamEntry
+	}{
+		{"/users/me", "/users/me", []ExplainBranch{BranchStatic, BranchStatic}, nil},
+		{"/users/42", "/users/:id", []ExplainBranch{BranchStatic, BranchParam}, []ParamEntry{{"id", "42"}}},
+		{"/hello/a/static/b", "/hello/:p1/static/:p2", []ExplainBranch{BranchStatic, BranchParam, BranchStatic, BranchParam},
+			[]ParamEntry{{"p1", "a"}, {"p2", "b"}}},
+		{"/hello/a", "/hello/*p", []ExplainBranch{BranchStatic, BranchParam, BranchClosestWildcard}, []ParamEntry{{"p", "a"}}},
+		{"/hello/a/other", "/hello/*p", []ExplainBranch{BranchStatic, BranchParam, BranchClosestWildcard}, []ParamEntry{{"p", "a/other"}}},
+		{"/other", "", []ExplainBranch{BranchNotFound}, nil},
+		{"/users/42/posts", "", []ExplainBranch{BranchStatic, BranchParam, BranchNotFound}, nil},
+	}
+
+	for i, tt := range tests {
+		e := tree.Explain(tt.path)
+
+		if tt.key == "" {
+			if e.Node != nil {
+				t.Fatalf("[%d] %s: expected to not be found but got: %s", i, tt.path, e.Node.String())
+			}
+		} else if e.Node == nil || e.Node.String() != tt.key {
+			t.Fatalf("[%d] %s: expected to match '%s' but got:\n%s", i, tt.path, tt.key, e)
+		}
+
+		if expected, got := len(tt.branches), len(e.Steps); expected != got {
+			t.Fatalf("[%d] %s: expected %d steps but got %d:\n%s", i, tt.path, expected, got, e)
+		}
+
+		for j, branch := range tt.branches {
+			if expected, got := branch, e.Steps[j].Branch; expected != got {
+				t.Fatalf("[%d:%d] %s: expected branch '%s' but got '%s':\n%s", i, j, tt.path, expected, got, e)
+			}
+		}
+
+		if expected, got := len(tt.params), len(e.Params); expected != got {
+			t.Fatalf("[%d] %s: expected %d params but got %d:\n%s", i, tt.path, expected, got, e)
+		}
+
+		for j, p := range tt.params {
+			if expected, got := p, e.Params[j]; expected != got {
+				t.Fatalf("[%d:%d] %s: expected param %v but got %v", i, j, tt.path, expected, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package muxie
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultMaxForwardDepth is the default limit of the nested `Mux#Forward` calls of a request.
+const DefaultMaxForwardDepth = 10
+
+// ErrForwardLoop is returned by `Mux#Forward` when the nested forwards of a request exceeded the `Mux#MaxForwardDepth`.
+var ErrForwardLoop = errors.New("muxie: forward loop detected")
+
+// ErrForwardPath is returned by `Mux#Forward` when the "path" does not start with a slash,
+// nothing is written to the response then.
+var ErrForwardPath = errors.New("muxie: forward path must start with a slash")
+
+// Forward serves the request through the route of another "path", i.e for legacy aliases and internal rewrites,
+// without a redirect round trip to the client. The "path" can contain a query, which replaces the request's one.
+// The route is searched with fresh parameters and the pooled response writer is reused,
+// the caller's parameters and route are restored when Forward returns.
+//
+// Forward calls can be nested, but when they exceed the `MaxForwardDepth`
+// the request is answered with 508 Loop Detected and `ErrForwardLoop` is returned.
+func (m *Mux) Forward(w http.ResponseWriter, r *http.Request, path string) error {
+	if len(path) == 0 || path[0] != pathSepB {
+		return ErrForwardPath
+	}
+
+	maxDepth := m.MaxForwardDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxForwardDepth
+	}
+
+	// the depth is carried through the request's context,
+	// so the limit holds even if the "w" is wrapped, i.e by a `Route#Use` middleware.
+	depth, _ := r.Context().Value(forwardDepthKey{}).(int)
+	if depth >= maxDepth {
+		Error(w, r, http.StatusLoopDetected)
+		return ErrForwardLoop
+	}
+
+	r2 := r.WithContext(context.WithValue(r.Context(), forwardDepthKey{}, depth+1))
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.RequestURI = path
+	if idx := strings.IndexByte(path, '?'); idx != -1 {
+		r2.URL.RawQuery = path[idx+1:]
+		path = path[:idx]
+	}
+	r2.URL.Path = path
+	r2.URL.RawPath = ""
+
+	pw, ok := w.(*paramsWriter)
+	if !ok {
+		m.ServeHTTP(w, r2)
+		return nil
+	}
+
+	// the caller's route state is restored after the forwarded route is served.
+	params := make(Params, len(pw.params))
+	copy(params, pw.params)
+	node, version, group := pw.node, pw.version, pw.group
+
+	// fresh parameters and route.
+	pw.params = pw.params[0:0]
+	pw.node = nil
+	pw.version = ""
+	m.dispatch(m.groupFor(path), pw, nil, r2, path)
+
+	pw.params = append(pw.params[0:0], params...)
+	pw.node, pw.version, pw.group = node, version, group
+	return nil
+}
+
+type forwardDepthKey struct{}
@@ -0,0 +1,79 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMuxForward(t *testing.T) {
+	mux := NewMux()
+	mux.HandleFunc("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "user %s (%s) %s", GetParam(w, "id"), GetRoute(w).Pattern(), r.URL.Query().Get("tab"))
+	})
+	mux.HandleFunc("/legacy/profile/:uid", func(w http.ResponseWriter, r *http.Request) {
+		mux.Forward(w, r, "/users/"+GetParam(w, "uid")+"?tab=profile")
+		// the caller's state is restored.
+		fmt.Fprintf(w, "|%s %s %d", GetRoute(w).Pattern(), GetParam(w, "uid"), len(GetParams(w)))
+	})
+
+	// a middleware which wraps the response writer, the loop is still detected.
+	var wrappedErr error
+	mux.HandleFunc("/wrapped", func(w http.ResponseWriter, r *http.Request) {
+		if err := mux.Forward(w, r, "/wrapped"); err != nil {
+			wrappedErr = err
+		}
+	}).Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(struct{ http.ResponseWriter }{w}, r)
+		})
+	})
+
+	var loopErr error
+	mux.HandleFunc("/loop/:n", func(w http.ResponseWriter, r *http.Request) {
+		if err := mux.Forward(w, r, "/loop/"+GetParam(w, "n")+"x"); err != nil {
+			loopErr = err
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/legacy/profile/42", nil))
+
+	if expected, got := "user 42 (/users/:id) profile|/legacy/profile/:uid 42 1", rec.Body.String(); expected != got {
+		t.Fatalf("expected to receive '%s' but got '%s'", expected, got)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loop/x", nil))
+
+	if expected, got := http.StatusLoopDetected, rec.Code; expected != got {
+		t.Fatalf("expected status code: %d but got %d", expected, got)
+	}
+
+	if loopErr != ErrForwardLoop {
+		t.Fatalf("expected forward loop error but got: %v", loopErr)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wrapped", nil))
+
+	if expected, got := http.StatusLoopDetected, rec.Code; expected != got {
+		t.Fatalf("expected status code: %d but got %d", expected, got)
+	}
+
+	if wrappedErr != ErrForwardLoop {
+		t.Fatalf("expected forward loop error under a wrapped writer but got: %v", wrappedErr)
+	}
+}
+
+func TestMuxForwardPath(t *testing.T) {
+	mux := NewMux()
+
+	for _, path := range []string{"", "?a=b", "users"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if err := mux.Forward(httptest.NewRecorder(), req, path); err != ErrForwardPath {
+			t.Fatalf("%q: expected forward path error but got: %v", path, err)
+		}
+	}
+}
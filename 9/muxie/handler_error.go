@@ -0,0 +1,172 @@
+package muxie
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// HandlerFuncE is a handler which returns an error instead of responding to it,
+// the error is passed to the `ErrorResponder` of the group that serves the request, see `Mux#HandleFuncE`.
+type HandlerFuncE func(w http.ResponseWriter, r *http.Request) error
+
+func (fn HandlerFuncE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := fn(w, r); err != nil {
+		RespondError(w, r, err)
+	}
+}
+
+// ErrorResponder responds to an error returned by a `HandlerFuncE`,
+// the matched route is available through the `GetRoute(w)`.
+type ErrorResponder func(w http.ResponseWriter, r *http.Request, err error)
+
+// DefaultErrorResponder responds with the error handler of the `StatusOf` the error, see `Error`,
+// except the `ValidationErrors` which are responded as a JSON 400 listing the violations, i.e
+//
+//	{"message":"Bad Request","violations":[{"field":"/name","message":"is required"}]}
+var DefaultErrorResponder ErrorResponder = func(w http.ResponseWriter, r *http.Request, err error) {
+	var violations ValidationErrors
+	if errors.As(err, &violations) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(struct {
+			Message    string           `json:"message"`
+			Violations ValidationErrors `json:"violations"`
+		}{http.StatusText(http.StatusBadRequest), violations})
+		return
+	}
+
+	Error(w, r, StatusOf(err))
+}
+
+// The errors that are mapped to their status codes by the `StatusOf`, they can be wrapped.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+)
+
+// StatusCoder can be implemented by errors to set their status code, see `StatusOf`.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// StatusError is an error with a status code.
+type StatusError struct {
+	Code int
+	Err  error
+}
+
+func (e *StatusError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the status code of the error.
+func (e *StatusError) StatusCode() int {
+	return e.Code
+}
+
+// ValidationError describes an invalid field of a request.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors is a list of `ValidationError`, its status code is 400 Bad Request.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i := range e {
+		messages[i] = e[i].Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// StatusCode returns 400.
+func (e ValidationErrors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// StatusOf returns the status code of an error:
+// the `StatusCoder`'s one, 404 for `ErrNotFound`, 401 for `ErrUnauthorized`, 403 for `ErrForbidden`,
+// 400 for `ValidationError` and `ParamError`, otherwise 500.
+func StatusOf(err error) int {
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return coder.StatusCode()
+	}
+
+	var (
+		validationErr ValidationError
+		paramErr      *ParamError
+	)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.As(err, &validationErr), errors.As(err, &paramErr):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// RespondError passes the "err" to the `ErrorResponder` of the group that serves the request,
+// it falls back to the `DefaultErrorResponder` if the "w" is not the one that the `Mux` passed to the handler.
+// Nothing is written if the handler has already written the response's status code.
+func RespondError(w http.ResponseWriter, r *http.Request, err error) {
+	pw, ok := w.(*paramsWriter)
+	if !ok || pw.group == nil {
+		DefaultErrorResponder(w, r, err)
+		return
+	}
+
+	if pw.status != 0 {
+		return
+	}
+
+	pw.group.errorResponder()(w, r, err)
+}
+
+// HandleFuncE registers an error-returning handler for "pattern", see `HandlerFuncE` and `ErrorResponder`.
+func (m *Mux) HandleFuncE(pattern string, handlerFunc func(http.ResponseWriter, *http.Request) error) *Route {
+	return m.Handle(pattern, HandlerFuncE(handlerFunc))
+}
+
+// SetErrorResponder sets the `ErrorResponder` of this group.
+func (m *Mux) SetErrorResponder(responder ErrorResponder) {
+	m.ErrorResponder = responder
+}
+
+func (m *Mux) errorResponder() ErrorResponder {
+	for g := m; g != nil; g = g.parent {
+		if g.ErrorResponder != nil {
+			return g.ErrorResponder
+		}
+	}
+
+	return DefaultErrorResponder
+}
@@ -0,0 +1,81 @@
+package muxie
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleFuncE(t *testing.T) {
+	mux := NewMux()
+	mux.HandleFuncE("/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		id, err := GetParams(w).Int("id")
+		if err != nil {
+			return err
+		}
+
+		if id != 1 {
+			return fmt.Errorf("user %d: %w", id, ErrNotFound)
+		}
+
+		fmt.Fprint(w, "user 1")
+		return nil
+	})
+	mux.HandleFuncE("/private", func(w http.ResponseWriter, r *http.Request) error {
+		return ErrUnauthorized
+	})
+	mux.HandleFuncE("/teapot", func(w http.ResponseWriter, r *http.Request) error {
+		return &StatusError{Code: http.StatusTeapot}
+	})
+	mux.HandleFuncE("/failure", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("database is down")
+	})
+
+	api := mux.Of("/api")
+	api.SetErrorResponder(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(StatusOf(err))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"route": GetRoute(w).Pattern(),
+			"error": err,
+		})
+	})
+	api.HandleFuncE("/users", func(w http.ResponseWriter, r *http.Request) error {
+		return ValidationErrors{{Field: "name", Message: "required"}}
+	})
+	api.HandleFuncE("/written", func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return errors.New("ignored")
+	})
+
+	tests := []struct {
+		path       string
+		statusCode int
+		body       string
+	}{
+		{"/users/1", http.StatusOK, "user 1"},
+		{"/users/2", http.StatusNotFound, "404 page not found\n"},
+		{"/users/notanumber", http.StatusBadRequest, "Bad Request\n"},
+		{"/private", http.StatusUnauthorized, "Unauthorized\n"},
+		{"/teapot", http.StatusTeapot, "I'm a teapot\n"},
+		{"/failure", http.StatusInternalServerError, "Internal Server Error\n"},
+		{"/api/users", http.StatusBadRequest, `{"error":[{"field":"name","message":"required"}],"route":"/api/users"}` + "\n"},
+		{"/api/written", http.StatusAccepted, ""},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s: expected status code: %d but got %d", i, tt.path, expected, got)
+		}
+
+		if expected, got := tt.body, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package muxie
+
+import (
+	"net/http"
+	"time"
+)
+
+// RouteEvent holds the routing information of a request, it is passed to the `Hooks`.
+type RouteEvent struct {
+	// Request is the incoming request, `Hooks#OnMatch` and `Hooks#OnNotFound`
+	// can replace it, i.e with a request which its context holds a tracing span,
+	// and the new one will be passed to the route's handler.
+	Request *http.Request
+	// Node is the matched route's node, its pattern is available through `Node#String`
+	// and its tag and data through the `Tag` and `Data` fields. It's nil when no route matched.
+	Node *Node
+	// Params are the request's parameters, they should not be retained after the hook's call.
+	Params []ParamEntry
+	// Lookup is the time spent to find the route.
+	Lookup time.Duration
+	// Status is the final response status code, available on `Hooks#OnServeDone`.
+	Status int
+	// Elapsed is the time spent to find the route and serve the request, available on `Hooks#OnServeDone`.
+	Elapsed time.Duration
+}
+
+// Pattern returns the matched route's pattern, i.e "/users/:id", or an empty string if no route matched.
+func (e *RouteEvent) Pattern() string {
+	if e.Node == nil {
+		return ""
+	}
+
+	return e.Node.key
+}
+
+// Hooks is the interface which can be registered through the `Mux#Hooks` field
+// in order to watch the routing lifecycle of requests, i.e for tracing and logging.
+type Hooks interface {
+	// OnMatch is called right after a route matched the request and before its handler.
+	OnMatch(e *RouteEvent)
+	// OnNotFound is called when no route matched the request.
+	OnNotFound(e *RouteEvent)
+	// OnServeDone is called after the request was served, matched or not.
+	OnServeDone(e *RouteEvent)
+}
+
+// HookFuncs implements the `Hooks` interface through optional functions.
+type HookFuncs struct {
+	Match     func(e *RouteEvent)
+	NotFound  func(e *RouteEvent)
+	ServeDone func(e *RouteEvent)
+}
+
+var _ Hooks = HookFuncs{}
+
+func (h HookFuncs) OnMatch(e *RouteEvent) {
+	if h.Match != nil {
+		h.Match(e)
+	}
+}
+
+func (h HookFuncs) OnNotFound(e *RouteEvent) {
+	if h.NotFound != nil {
+		h.NotFound(e)
+	}
+}
+
+func (h HookFuncs) OnServeDone(e *RouteEvent) {
+	if h.ServeDone != nil {
+		h.ServeDone(e)
+	}
+}
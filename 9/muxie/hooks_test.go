@@ -0,0 +1,79 @@
+package muxie
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// spanRecorder is an in-memory, OpenTelemetry-like, tracer built on top of the `Hooks`.
+type spanRecorder struct {
+	ended []*span
+}
+
+type span struct {
+	name   string
+	attrs  map[string]string
+	status int
+}
+
+type spanContextKey struct{}
+
+func (rec *spanRecorder) OnMatch(e *RouteEvent) {
+	s := &span{name: e.Request.Method + " " + e.Pattern(), attrs: map[string]string{"route.tag": e.Node.Tag}}
+	for _, p := range e.Params {
+		s.attrs["route.param."+p.Key] = p.Value
+	}
+	e.Request = e.Request.WithContext(context.WithValue(e.Request.Context(), spanContextKey{}, s))
+}
+
+func (rec *spanRecorder) OnNotFound(e *RouteEvent) {
+	s := &span{name: e.Request.Method + " not_found", attrs: map[string]string{}}
+	e.Request = e.Request.WithContext(context.WithValue(e.Request.Context(), spanContextKey{}, s))
+}
+
+func (rec *spanRecorder) OnServeDone(e *RouteEvent) {
+	s := e.Request.Context().Value(spanContextKey{}).(*span)
+	s.status = e.Status
+	rec.ended = append(rec.ended, s)
+}
+
+func TestMuxHooks(t *testing.T) {
+	rec := new(spanRecorder)
+
+	mux := NewMux()
+	mux.Hooks = rec
+	mux.Routes.Insert("/users/:id", WithTag("user"), WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(spanContextKey{}).(*span); !ok {
+			t.Fatalf("expected span to be available to the handler")
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})))
+
+	for _, path := range []string{"/users/42", "/other"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if expected, got := 2, len(rec.ended); expected != got {
+		t.Fatalf("expected %d ended spans but got %d", expected, got)
+	}
+
+	s := rec.ended[0]
+	if expected, got := "GET /users/:id", s.name; expected != got {
+		t.Fatalf("expected span name: '%s' but got '%s'", expected, got)
+	}
+	if expected, got := "user", s.attrs["route.tag"]; expected != got {
+		t.Fatalf("expected route tag attribute: '%s' but got '%s'", expected, got)
+	}
+	if expected, got := "42", s.attrs["route.param.id"]; expected != got {
+		t.Fatalf("expected route param attribute: '%s' but got '%s'", expected, got)
+	}
+	if expected, got := http.StatusAccepted, s.status; expected != got {
+		t.Fatalf("expected span status: %d but got %d", expected, got)
+	}
+
+	if expected, got := "GET not_found", rec.ended[1].name; expected != got {
+		t.Fatalf("expected span name: '%s' but got '%s'", expected, got)
+	}
+}
@@ -0,0 +1,145 @@
+package muxie
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// DefaultMaxBodySize is the default limit of the request bodies decoded by the `JSON` handlers, 1MB.
+const DefaultMaxBodySize int64 = 1 << 20
+
+// Validator can be implemented by the requests of the `JSON` handlers,
+// its `Validate` is called after decoding, i.e to return `ValidationErrors`.
+type Validator interface {
+	Validate() error
+}
+
+// JSONHandler is the handler of a JSON endpoint, see `JSON`.
+type JSONHandler[Req, Resp any] struct {
+	fn          func(context.Context, Req) (Resp, error)
+	maxBodySize int64
+	status      int
+	bindParams  bool // true if the "Req" is a struct, its `param` tagged fields are filled from the path parameters.
+}
+
+// JSON returns a handler which decodes the request's JSON body and path parameters into a "Req",
+// calls the "fn" and encodes its "Resp" as JSON, i.e
+//
+//	type getUser struct {
+//		ID int64 `param:"id"`
+//	}
+//
+//	mux.Handle("/users/:id", muxie.JSON(func(ctx context.Context, req getUser) (*User, error) {...}))
+//
+// The path parameters are bound through the `Params#Bind`, after the body, so they have priority.
+// If the "Req" implements the `Validator` then it's validated before the "fn" is called.
+// The decoding, validation and "fn" errors are passed to the `RespondError`:
+// 400 for invalid bodies and parameters, 413 for bodies larger than the `MaxBodySize` and
+// 415 for non-JSON content types, the returned errors are mapped by the `StatusOf`.
+// A nil "Resp" is answered with 204 No Content.
+func JSON[Req, Resp any](fn func(context.Context, Req) (Resp, error)) *JSONHandler[Req, Resp] {
+	typ := reflect.TypeOf((*Req)(nil)).Elem()
+
+	return &JSONHandler[Req, Resp]{
+		fn:          fn,
+		maxBodySize: DefaultMaxBodySize,
+		status:      http.StatusOK,
+		bindParams:  typ.Kind() == reflect.Struct,
+	}
+}
+
+// MaxBodySize sets the limit of the request body, a negative value means no limit,
+// defaults to the `DefaultMaxBodySize`.
+func (h *JSONHandler[Req, Resp]) MaxBodySize(limit int64) *JSONHandler[Req, Resp] {
+	h.maxBodySize = limit
+	return h
+}
+
+func (h *JSONHandler[Req, Resp]) bodyLimit() int64 {
+	return h.maxBodySize
+}
+
+// Status sets the status code of the successful responses, i.e 201 Created, defaults to 200.
+func (h *JSONHandler[Req, Resp]) Status(statusCode int) *JSONHandler[Req, Resp] {
+	h.status = statusCode
+	return h
+}
+
+func (h *JSONHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Req
+	if err := h.decode(w, r, &req); err != nil {
+		RespondError(w, r, err)
+		return
+	}
+
+	resp, err := h.fn(r.Context(), req)
+	if err != nil {
+		RespondError(w, r, err)
+		return
+	}
+
+	if isNilValue(reflect.ValueOf(&resp).Elem()) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(h.status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *JSONHandler[Req, Resp]) decode(w http.ResponseWriter, r *http.Request, req *Req) error {
+	if r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0 {
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			if mediaType, _, _ := mime.ParseMediaType(contentType); !isJSONMediaType(mediaType) {
+				return &StatusError{Code: http.StatusUnsupportedMediaType}
+			}
+		}
+
+		body := r.Body
+		if h.maxBodySize >= 0 {
+			body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+		}
+
+		if err := json.NewDecoder(body).Decode(req); err != nil && err != io.EOF {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return &StatusError{Code: http.StatusRequestEntityTooLarge, Err: err}
+			}
+
+			return &StatusError{Code: http.StatusBadRequest, Err: fmt.Errorf("invalid JSON body: %w", err)}
+		}
+	}
+
+	if h.bindParams {
+		params := GetParams(w)
+		if params == nil {
+			// the "w" is wrapped, i.e by a middleware, see `ParamsToContext`.
+			params = GetRequestParams(r)
+		}
+
+		if err := params.Bind(req); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := interface{}(req).(Validator); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// isJSONMediaType reports whether the "mediaType" is "application/json"
+// or a JSON based one, i.e "application/problem+json".
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
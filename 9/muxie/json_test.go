@@ -0,0 +1,95 @@
+package muxie
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type updateUserRequest struct {
+	ID   int64  `param:"id" json:"-"`
+	Name string `json:"name"`
+}
+
+func (req updateUserRequest) Validate() error {
+	if req.Name == "" {
+		return ValidationErrors{{Field: "name", Message: "required"}}
+	}
+
+	return nil
+}
+
+type userResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSON(t *testing.T) {
+	mux := NewMux()
+	mux.Handle("/users/:id", JSON(func(ctx context.Context, req updateUserRequest) (*userResponse, error) {
+		if req.ID == 404 {
+			return nil, ErrNotFound
+		}
+
+		if req.ID == 0 {
+			return nil, nil
+		}
+
+		return &userResponse{ID: req.ID, Name: req.Name}, nil
+	}).MaxBodySize(32).Status(http.StatusCreated))
+	// the params writer is wrapped by a middleware.
+	mux.Handle("/wrapped/:id", ParamsToContext(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		JSON(func(ctx context.Context, req updateUserRequest) (*userResponse, error) {
+			return &userResponse{ID: req.ID, Name: req.Name}, nil
+		}).ServeHTTP(struct{ http.ResponseWriter }{w}, r)
+	})))
+	mux.Handle("/ping", JSON(func(ctx context.Context, req struct{}) (string, error) {
+		if ctx == nil {
+			return "", errors.New("nil context")
+		}
+
+		return "pong", nil
+	}))
+
+	tests := []struct {
+		path        string
+		contentType string
+		body        string
+		statusCode  int
+		response    string
+	}{
+		{"/users/42", "application/json", `{"name":"kataras"}`, http.StatusCreated, `{"id":42,"name":"kataras"}` + "\n"},
+		{"/users/42", "", `{"id":1,"name":"kataras"}`, http.StatusCreated, `{"id":42,"name":"kataras"}` + "\n"},
+		{"/users/0", "application/json", `{"name":"kataras"}`, http.StatusNoContent, ""},
+		{"/users/404", "application/json", `{"name":"kataras"}`, http.StatusNotFound, "404 page not found\n"},
+		{"/users/42", "application/json", `{}`, http.StatusBadRequest, `{"message":"Bad Request","violations":[{"field":"name","message":"required"}]}` + "\n"},
+		{"/users/42", "application/json", `{"name":`, http.StatusBadRequest, "Bad Request\n"},
+		{"/users/notanumber", "application/json", `{"name":"kataras"}`, http.StatusBadRequest, "Bad Request\n"},
+		{"/users/42", "application/merge-patch+json", `{"name":"kataras"}`, http.StatusCreated, `{"id":42,"name":"kataras"}` + "\n"},
+		{"/wrapped/42", "application/json", `{"name":"kataras"}`, http.StatusOK, `{"id":42,"name":"kataras"}` + "\n"},
+		{"/users/42", "text/plain", `{"name":"kataras"}`, http.StatusUnsupportedMediaType, "Unsupported Media Type\n"},
+		{"/users/42", "application/json", `{"name":"` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge, "Request Entity Too Large\n"},
+		{"/ping", "", "", http.StatusOK, `"pong"` + "\n"},
+	}
+
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s: expected status code: %d but got %d", i, tt.path, expected, got)
+		}
+
+		if expected, got := tt.response, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package muxie
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Matcher reports whether a request, which its path is already matched against a route,
+// can be served by a specific handler of that route, see `WithMatch` and `Mux#HandleMatch`.
+type Matcher interface {
+	Match(r *http.Request) bool
+}
+
+// MatcherFunc is the func adapter of a `Matcher`, can be used for custom request matchers.
+type MatcherFunc func(r *http.Request) bool
+
+func (fn MatcherFunc) Match(r *http.Request) bool {
+	return fn(r)
+}
+
+// Header matches if one of the request's "key" header values is equal to "value",
+// if "value" is empty then it matches if the header exists at all.
+func Header(key, value string) Matcher {
+	key = http.CanonicalHeaderKey(key)
+	return MatcherFunc(func(r *http.Request) bool {
+		values, ok := r.Header[key]
+		if !ok {
+			return false
+		}
+
+		if value == "" {
+			return true
+		}
+
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// Query matches if the url query parameter "key" is equal to "value",
+// if "value" is empty then it matches if the query parameter exists at all.
+func Query(key, value string) Matcher {
+	return MatcherFunc(func(r *http.Request) bool {
+		values, ok := r.URL.Query()[key]
+		if !ok {
+			return false
+		}
+
+		if value == "" {
+			return true
+		}
+
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// Scheme matches if the request's scheme is equal to "scheme", i.e "https".
+func Scheme(scheme string) Matcher {
+	return MatcherFunc(func(r *http.Request) bool {
+		return strings.EqualFold(requestScheme(r), scheme)
+	})
+}
+
+func requestScheme(r *http.Request) string {
+	if r.URL.Scheme != "" {
+		return r.URL.Scheme
+	}
+
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// candidate is a handler of a route which is served
+// only when all of its matchers are passing.
+type candidate struct {
+	matchers []Matcher
+	handler  http.Handler
+}
+
+func (c *candidate) match(r *http.Request) bool {
+	for _, m := range c.matchers {
+		if !m.Match(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,73 @@
+package muxie
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMuxHandleMatch(t *testing.T) {
+	writeText := func(text string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s:%s", text, GetParam(w, "id"))
+		}
+	}
+
+	mux := NewMux()
+	mux.Handle("/users/:id", writeText("default"))
+	mux.HandleMatch("/users/:id", writeText("v2"), Header("X-API-Version", "2"))
+	mux.HandleMatch("/users/:id", writeText("json"), Header("Accept", "application/json"))
+	mux.HandleMatch("/users/:id", writeText("debug"), Query("debug", ""), Scheme("http"))
+	mux.HandleMatch("/users/:id", writeText("custom"), MatcherFunc(func(r *http.Request) bool {
+		return r.Header.Get("X-Custom") != ""
+	}))
+	// no fallback handler.
+	mux.HandleMatch("/only", writeText("only"), Header("X-Only", ""))
+
+	tests := []struct {
+		path     string
+		header   http.Header
+		expected string
+	}{
+		{"/users/1", nil, "default:1"},
+		{"/users/2", http.Header{"X-Api-Version": {"2"}}, "v2:2"},
+		{"/users/3", http.Header{"Accept": {"application/json"}}, "json:3"},
+		// first registered wins.
+		{"/users/4", http.Header{"Accept": {"application/json"}, "X-Api-Version": {"2"}}, "v2:4"},
+		{"/users/5?debug", nil, "debug:5"},
+		{"/users/6?other=debug", nil, "default:6"},
+		{"/users/7", http.Header{"X-Custom": {"1"}}, "custom:7"},
+		{"/only", http.Header{"X-Only": {"1"}}, "only:"},
+		{"/only", nil, "404 page not found\n"},
+	}
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for i, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range tt.header {
+			req.Header[k] = v
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, got := tt.expected, string(body); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package muxie
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MethodHandler routes a request to a handler based on its method,
+// it is registered as the handler of a route, i.e
+//
+//	mux.Handle("/users", muxie.Methods().
+//		HandleFunc(http.MethodGet, listUsers).
+//		HandleFunc(http.MethodPost, createUser))
+//
+// HEAD requests are served by the GET handler with the response body discarded
+// and OPTIONS requests are answered with an "Allow" header of the registered methods,
+// both can be overridden by registering a HEAD or an OPTIONS handler.
+// Any other, not registered, method is answered with 405 Method Not Allowed, see `Error`.
+type MethodHandler struct {
+	handlers map[string]http.Handler
+	// the value of the "Allow" header, it is rebuilt on each `Handle`.
+	allow string
+}
+
+// Methods returns a new, empty, `MethodHandler`.
+func Methods() *MethodHandler {
+	return &MethodHandler{handlers: make(map[string]http.Handler)}
+}
+
+// Handle registers a "handler" for the request method "method", i.e http.MethodGet.
+func (m *MethodHandler) Handle(method string, handler http.Handler) *MethodHandler {
+	m.handlers[strings.ToUpper(method)] = handler
+	m.allow = strings.Join(m.AllowedMethods(), ", ")
+	return m
+}
+
+// HandleFunc registers a "handlerFunc" for the request method "method", i.e http.MethodGet.
+func (m *MethodHandler) HandleFunc(method string, handlerFunc func(http.ResponseWriter, *http.Request)) *MethodHandler {
+	return m.Handle(method, http.HandlerFunc(handlerFunc))
+}
+
+// AllowedMethods returns the sorted methods that this handler responds to,
+// including the automatic HEAD and OPTIONS ones.
+func (m *MethodHandler) AllowedMethods() []string {
+	methods := make([]string, 0, len(m.handlers)+2)
+	for method := range m.handlers {
+		methods = append(methods, method)
+	}
+
+	if _, ok := m.handlers[http.MethodHead]; !ok {
+		if _, ok = m.handlers[http.MethodGet]; ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+
+	if _, ok := m.handlers[http.MethodOptions]; !ok {
+		methods = append(methods, http.MethodOptions)
+	}
+
+	sort.Strings(methods)
+	return methods
+}
+
+func (m *MethodHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h, ok := m.handlers[r.Method]; ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		if h, ok := m.handlers[http.MethodGet]; ok {
+			h.ServeHTTP(discardBody(w), r)
+			return
+		}
+	case http.MethodOptions:
+		w.Header().Set("Allow", m.allow)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Allow", m.allow)
+	Error(w, r, http.StatusMethodNotAllowed)
+}
+
+// discardBody returns a writer which discards the response body but keeps the headers,
+// the params writer is kept as it is so the parameters are still accessible.
+func discardBody(w http.ResponseWriter) http.ResponseWriter {
+	if pw, ok := w.(*paramsWriter); ok {
+		pw.discardBody = true
+		return pw
+	}
+
+	return &headResponseWriter{w}
+}
+
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Methods returns the registered request methods of the node,
+// collected by its `MethodHandler` handlers, if any.
+func (n *Node) Methods() []string {
+	var methods []string
+	add := func(h http.Handler) {
+		mh, ok := h.(*MethodHandler)
+		if !ok {
+			return
+		}
+
+		for _, method := range mh.AllowedMethods() {
+			if !containsString(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+	}
+
+	add(n.Handler)
+	for _, c := range n.candidates {
+		add(c.handler)
+	}
+	for _, v := range n.versions {
+		add(v.handler)
+	}
+
+	sort.Strings(methods)
+	return methods
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
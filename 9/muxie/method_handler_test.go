@@ -0,0 +1,85 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMethodHandler(t *testing.T) {
+	mux := NewMux()
+	mux.Handle("/users/:id", Methods().
+		HandleFunc(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-User", GetParam(w, "id"))
+			fmt.Fprintf(w, "GET user %s", GetParam(w, "id"))
+		}).
+		HandleFunc(http.MethodDelete, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "DELETE user %s", GetParam(w, "id"))
+		}))
+
+	mux.Handle("/custom", Methods().
+		HandleFunc(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "GET custom")
+		}).
+		HandleFunc(http.MethodHead, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Custom-Head", "1")
+		}).
+		HandleFunc(http.MethodOptions, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "custom options")
+		}))
+
+	tests := []struct {
+		method     string
+		path       string
+		statusCode int
+		body       string
+		header     http.Header
+	}{
+		{http.MethodGet, "/users/42", http.StatusOK, "GET user 42", http.Header{"X-User": {"42"}}},
+		{http.MethodDelete, "/users/42", http.StatusOK, "DELETE user 42", nil},
+		{http.MethodHead, "/users/42", http.StatusOK, "", http.Header{"X-User": {"42"}}},
+		{http.MethodOptions, "/users/42", http.StatusNoContent, "", http.Header{"Allow": {"DELETE, GET, HEAD, OPTIONS"}}},
+		{http.MethodPost, "/users/42", http.StatusMethodNotAllowed, "Method Not Allowed\n", http.Header{"Allow": {"DELETE, GET, HEAD, OPTIONS"}}},
+		// overridden.
+		{http.MethodHead, "/custom", http.StatusOK, "", http.Header{"X-Custom-Head": {"1"}}},
+		{http.MethodOptions, "/custom", http.StatusOK, "custom options", nil},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s %s: expected status code: %d but got %d", i, tt.method, tt.path, expected, got)
+		}
+
+		if expected, got := tt.body, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s %s: expected to receive '%s' but got '%s'", i, tt.method, tt.path, expected, got)
+		}
+
+		for key := range tt.header {
+			if expected, got := tt.header.Get(key), rec.Header().Get(key); expected != got {
+				t.Fatalf("[%d] %s %s: expected header '%s' to be '%s' but got '%s'", i, tt.method, tt.path, key, expected, got)
+			}
+		}
+	}
+
+	if expected, got := []string{"DELETE", "GET", "HEAD", "OPTIONS"}, mux.Routes.Search("/users/1", new(paramsWriter)).Methods(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected node's methods: %v but got %v", expected, got)
+	}
+}
+
+func TestMethodHandlerOptionsWithMatchers(t *testing.T) {
+	mux := NewMux()
+	mux.Handle("/items", Methods().HandleFunc(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {}))
+	mux.HandleMatch("/items", Methods().HandleFunc(http.MethodPut, func(w http.ResponseWriter, r *http.Request) {}), Header("X-Admin", ""))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/items", nil))
+
+	if expected, got := "GET, HEAD, OPTIONS, PUT", rec.Header().Get("Allow"); expected != got {
+		t.Fatalf("expected allow header: '%s' but got '%s'", expected, got)
+	}
+}
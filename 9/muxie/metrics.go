@@ -0,0 +1,227 @@
+package muxie
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMetricsBuckets are the default request duration histogram buckets, in seconds.
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collects request counts, latency histograms and in-flight gauges
+// labelled by the route's pattern (not the request path, so the labels are limited to the registered routes).
+// Set it to the `Mux#Metrics` field to enable the instrumented mode
+// and register it as a route in order to expose them in the Prometheus text format, i.e
+//
+//	metrics := muxie.NewMetrics()
+//	mux.Metrics = metrics
+//	mux.Handle("/metrics", metrics)
+type Metrics struct {
+	// Namespace is the prefix of the metric names, defaults to "muxie".
+	Namespace string
+	// Buckets are the request duration histogram's upper bounds, in seconds.
+	// Defaults to the `DefaultMetricsBuckets`, it should not be changed after the first request.
+	Buckets []float64
+	// UnmatchedLabel is the route label of the requests that didn't match any route, defaults to "unmatched".
+	UnmatchedLabel string
+
+	mu       sync.RWMutex
+	routes   map[string]*routeMetrics
+	excluded map[string]struct{}
+}
+
+// NewMetrics returns a new, empty, `Metrics` collector.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Namespace:      "muxie",
+		Buckets:        DefaultMetricsBuckets,
+		UnmatchedLabel: "unmatched",
+		routes:         make(map[string]*routeMetrics),
+		excluded:       make(map[string]struct{}),
+	}
+}
+
+// Exclude excludes routes by their registered patterns, i.e "/metrics" or "/health",
+// from the collected metrics.
+func (m *Metrics) Exclude(patterns ...string) *Metrics {
+	m.mu.Lock()
+	for _, pattern := range patterns {
+		m.excluded[pattern] = struct{}{}
+		delete(m.routes, pattern)
+	}
+	m.mu.Unlock()
+	return m
+}
+
+type routeMetrics struct {
+	inFlight int64 // atomic.
+
+	mu      sync.Mutex
+	counts  map[requestLabels]uint64
+	buckets []uint64 // non-cumulative.
+	sum     float64
+	count   uint64
+}
+
+type requestLabels struct {
+	method string
+	code   int
+}
+
+func (m *Metrics) route(label string) *routeMetrics {
+	m.mu.RLock()
+	rm, ok := m.routes[label]
+	_, excluded := m.excluded[label]
+	m.mu.RUnlock()
+
+	if ok || excluded {
+		return rm
+	}
+
+	m.mu.Lock()
+	if rm, ok = m.routes[label]; !ok {
+		rm = &routeMetrics{
+			counts:  make(map[requestLabels]uint64),
+			buckets: make([]uint64, len(m.Buckets)),
+		}
+		m.routes[label] = rm
+	}
+	m.mu.Unlock()
+
+	return rm
+}
+
+func (m *Metrics) serve(mux *Mux, h http.Handler, n *Node, pw *paramsWriter, r *http.Request) {
+	label := m.UnmatchedLabel
+	if h != nil {
+		label = n.key
+	}
+
+	rm := m.route(label)
+	if rm == nil { // excluded.
+		mux.serve(h, n, pw, r)
+		return
+	}
+
+	atomic.AddInt64(&rm.inFlight, 1)
+	start := time.Now()
+	defer func() {
+		atomic.AddInt64(&rm.inFlight, -1)
+		rm.observe(m.Buckets, r.Method, pw.statusCode(), time.Since(start))
+	}()
+
+	mux.serve(h, n, pw, r)
+}
+
+func (rm *routeMetrics) observe(buckets []float64, method string, code int, d time.Duration) {
+	seconds := d.Seconds()
+	labels := requestLabels{method: metricsMethod(method), code: code}
+
+	rm.mu.Lock()
+	rm.counts[labels]++
+	for i, upperBound := range buckets {
+		if seconds <= upperBound {
+			rm.buckets[i]++
+			break
+		}
+	}
+	rm.sum += seconds
+	rm.count++
+	rm.mu.Unlock()
+}
+
+// metricsMethod limits the method label to the standard methods.
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// ServeHTTP writes the collected metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	m.writeText(bw)
+	bw.Flush()
+}
+
+func (m *Metrics) writeText(w io.Writer) {
+	m.mu.RLock()
+	labels := make([]string, 0, len(m.routes))
+	for label := range m.routes {
+		labels = append(labels, label)
+	}
+	routes := make([]*routeMetrics, len(labels))
+	sort.Strings(labels)
+	for i, label := range labels {
+		routes[i] = m.routes[label]
+	}
+	m.mu.RUnlock()
+
+	name := m.Namespace + "_requests_total"
+	fmt.Fprintf(w, "# HELP %s Total number of HTTP requests by route, method and status code.\n", name)
+	fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	for i, rm := range routes {
+		rm.mu.Lock()
+		keys := make([]requestLabels, 0, len(rm.counts))
+		for k := range rm.counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].method == keys[j].method {
+				return keys[i].code < keys[j].code
+			}
+			return keys[i].method < keys[j].method
+		})
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s{route=\"%s\",method=\"%s\",code=\"%d\"} %d\n", name, escapeLabelValue(labels[i]), k.method, k.code, rm.counts[k])
+		}
+		rm.mu.Unlock()
+	}
+
+	name = m.Namespace + "_request_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s HTTP request latencies in seconds by route.\n", name)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	for i, rm := range routes {
+		route := escapeLabelValue(labels[i])
+		rm.mu.Lock()
+		var cumulative uint64
+		for j, upperBound := range m.Buckets {
+			cumulative += rm.buckets[j]
+			fmt.Fprintf(w, "%s_bucket{route=\"%s\",le=\"%s\"} %d\n", name, route, formatFloat(upperBound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{route=\"%s\",le=\"+Inf\"} %d\n", name, route, rm.count)
+		fmt.Fprintf(w, "%s_sum{route=\"%s\"} %s\n", name, route, formatFloat(rm.sum))
+		fmt.Fprintf(w, "%s_count{route=\"%s\"} %d\n", name, route, rm.count)
+		rm.mu.Unlock()
+	}
+
+	name = m.Namespace + "_requests_in_flight"
+	fmt.Fprintf(w, "# HELP %s Number of HTTP requests currently being served by route.\n", name)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+	for i, rm := range routes {
+		fmt.Fprintf(w, "%s{route=\"%s\"} %d\n", name, escapeLabelValue(labels[i]), atomic.LoadInt64(&rm.inFlight))
+	}
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
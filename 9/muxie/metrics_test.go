@@ -0,0 +1,73 @@
+package muxie
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMuxMetrics(t *testing.T) {
+	metrics := NewMetrics().Exclude("/metrics")
+
+	mux := NewMux()
+	mux.Metrics = metrics
+	mux.Handle("/metrics", metrics)
+	mux.HandleFunc("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		if GetParam(w, "id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, "user %s", GetParam(w, "id"))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0", "/unknown/path", "/metrics"} {
+		res, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	res, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "text/plain; version=0.0.4; charset=utf-8", res.Header.Get("Content-Type"); expected != got {
+		t.Fatalf("expected content type: '%s' but got '%s'", expected, got)
+	}
+
+	text := string(body)
+	for _, expected := range []string{
+		"# TYPE muxie_requests_total counter\n",
+		`muxie_requests_total{route="/users/:id",method="GET",code="200"} 2` + "\n",
+		`muxie_requests_total{route="/users/:id",method="GET",code="404"} 1` + "\n",
+		`muxie_requests_total{route="unmatched",method="GET",code="404"} 1` + "\n",
+		"# TYPE muxie_request_duration_seconds histogram\n",
+		`muxie_request_duration_seconds_bucket{route="/users/:id",le="+Inf"} 3` + "\n",
+		`muxie_request_duration_seconds_count{route="/users/:id"} 3` + "\n",
+		"# TYPE muxie_requests_in_flight gauge\n",
+		`muxie_requests_in_flight{route="/users/:id"} 0` + "\n",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("expected metrics to contain:\n%s\nbut got:\n%s", expected, text)
+		}
+	}
+
+	if strings.Contains(text, `route="/metrics"`) {
+		t.Fatalf("expected excluded route to not be collected but got:\n%s", text)
+	}
+}
@@ -0,0 +1,66 @@
+package muxie
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mountParamKey is the wildcard parameter's name of the mounted handlers' routes,
+// it is removed before the mounted handler is called.
+const mountParamKey = "muxie_mount_path"
+
+// Mount registers a foreign "handler", i.e a separately built `Mux`, a pprof handler or a third-party router,
+// to serve the "prefix" and everything under it.
+// The "prefix" is stripped from the request's path before the "handler" is called,
+// i.e `mux.Mount("/admin", adminMux)` will serve the "/admin/users" through the "/users" of the "adminMux".
+//
+// The "prefix" can contain named parameters, i.e "/tenants/:tid",
+// and a mounted muxie `Mux` will still have access to them through `GetParam`.
+func (m *Mux) Mount(prefix string, handler http.Handler) {
+	prefix = strings.Trim(prefix, pathSep)
+	if prefix != "" {
+		prefix = pathSep + prefix
+	}
+
+	// the rest of the path is resolved by the number of the prefix's segments and not by the wildcard parameter,
+	// because the route can be matched as the closest wildcard of a deeper sibling,
+	// i.e "/tenants/:tid/admin/users/:id/edit" for the "/tenants/acme/admin/users/42".
+	segments := strings.Count(m.root+prefix, pathSep)
+
+	mounted := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pw, ok := w.(*paramsWriter); ok {
+			pw.remove(mountParamKey)
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = stripSegments(r.URL.Path, segments)
+		r2.URL.RawPath = ""
+
+		handler.ServeHTTP(w, r2)
+	})
+
+	m.Handle(prefix+pathSep+WildcardParamStart+mountParamKey, mounted)
+	if prefix != "" {
+		m.Handle(prefix, mounted)
+	} else {
+		m.Handle(pathSep, mounted)
+	}
+}
+
+// stripSegments removes the first "n" segments of the "path", the result starts with a slash.
+func stripSegments(path string, n int) string {
+	for i := 0; i < n; i++ {
+		idx := strings.IndexByte(path[1:], pathSepB)
+		if idx == -1 {
+			return pathSep
+		}
+
+		path = path[idx+1:]
+	}
+
+	return path
+}
@@ -0,0 +1,65 @@
+package muxie
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMuxMount(t *testing.T) {
+	adminMux := NewMux()
+	adminMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "admin index of tenant %s", GetParam(w, "tid"))
+	})
+	adminMux.HandleFunc("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "admin user %s of tenant %s", GetParam(w, "id"), GetParam(w, "tid"))
+	})
+
+	mux := NewMux()
+	mux.Mount("/tenants/:tid/admin", adminMux)
+	// a deeper sibling, the mount is matched as its closest wildcard.
+	mux.HandleFunc("/tenants/:tid/admin/users/:id/edit", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "edit user %s of tenant %s", GetParam(w, "id"), GetParam(w, "tid"))
+	})
+	mux.Mount("/debug/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "debug %s params: %d", r.URL.Path, len(GetParams(w)))
+	}))
+	mux.Of("/v1").Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "static %s", r.URL.Path)
+	}))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/tenants/acme/admin", "admin index of tenant acme"},
+		{"/tenants/acme/admin/", "admin index of tenant acme"},
+		{"/tenants/acme/admin/users/42", "admin user 42 of tenant acme"},
+		{"/tenants/acme/admin/users/42/edit", "edit user 42 of tenant acme"},
+		{"/debug", "debug / params: 0"},
+		{"/debug/pprof/heap", "debug /pprof/heap params: 0"},
+		{"/v1/static/css/main.css", "static /css/main.css"},
+	}
+
+	for i, tt := range tests {
+		res, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, got := tt.expected, string(body); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
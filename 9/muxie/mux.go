@@ -6,15 +6,69 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 )
 
+// Mux is the http router, a group of it, which can be created through `Of`,
+// shares the same `Routes` and inherits the settings of its parent, unless overridden.
+// The route-related settings (path correction, error handlers, maintenance, recover, CORS and versioning)
+// of the group that the request's route (or path) belongs to are used,
+// while the instrumentation ones (metrics, hooks and route context) of the `Mux` that serves the request.
 type Mux struct {
 	PathCorrection bool
 	Routes         *Trie
 
+	// NotFoundHandler responds to the requests that no route matched,
+	// defaults to the 404 Not Found handler, see `HandleError`.
+	NotFoundHandler http.Handler
+
+	// Recover, if true, recovers from handlers' panics,
+	// responds with the `PanicHandler` and calls the `OnPanic` hook.
+	Recover bool
+	// PanicHandler responds to a recovered panic, defaults to the 500 Internal Server Error handler, see `HandleError`.
+	// It's resolved level by level, so a closer group's 500 error handler wins over a parent's PanicHandler.
+	PanicHandler http.Handler
+	// OnPanic, if not nil, is called with the matched route, params and stack of a recovered panic.
+	OnPanic func(PanicInfo)
+
+	// ErrorResponder responds to the errors returned by the `HandlerFuncE` handlers,
+	// defaults to the `DefaultErrorResponder`.
+	ErrorResponder ErrorResponder
+
+	// CORS, if not nil, enables the Cross-Origin Resource Sharing handling.
+	CORS *CORS
+
+	// Versioning, if not nil, overrides the `DefaultVersioning` settings, see `HandleVersion`.
+	Versioning *Versioning
+
+	// Metrics, if not nil, collects per-route request metrics, see `NewMetrics`.
+	Metrics *Metrics
+
+	// RouteContext, if true, stores the matched route to the request's context, see `GetRequestRoute`.
+	// Note that it costs an allocation per request, the `GetRoute` is preferred when the response writer is available.
+	RouteContext bool
+
+	// MaxForwardDepth limits the nested `Forward` calls of a request, defaults to `DefaultMaxForwardDepth`.
+	MaxForwardDepth int
+
+	// Hooks, if not nil, are notified about the routing lifecycle of each request, see `HookFuncs` too.
+	Hooks Hooks
+
 	paramsPool *sync.Pool
 	root       string
 
+	parent   *Mux
+	children []*Mux
+	// true when the bool settings were explicitly set through `SetPathCorrection` and `SetRecover`,
+	// so a false value does not inherit the parent's one.
+	pathCorrectionSet bool
+	recoverSet        bool
+	meta              map[string]interface{}
+	errorHandlers     map[int]ErrorHandler
+	maintenance       int32 // atomic.
+	// the named routes, stored on the root `Mux` only, see `Route#Name`.
+	named map[string]*Route
+
 	// TODO: somehow make the separator to be able to chagne by mux or by some options, configs...
 }
 
@@ -30,18 +84,34 @@ func NewMux() *Mux {
 	}
 }
 
-func (m *Mux) Handle(pattern string, handler http.Handler) {
-	m.Routes.Insert(m.root+pattern, WithHandler(handler))
+// Handle registers a "handler" for the "pattern",
+// the returned `Route` can be used to configure it further.
+func (m *Mux) Handle(pattern string, handler http.Handler) *Route {
+	n := m.Routes.insertNode(m.root+pattern, withMux(m))
+	mh, _ := n.Handler.(*MethodHandler)
+	// same pattern replaces the handler, its matchers and versions are kept.
+	n.Handler = handler
+	return &Route{node: n, mux: m, handler: handler, methodHandler: mh}
+}
+
+func (m *Mux) HandleFunc(pattern string, handlerFunc func(http.ResponseWriter, *http.Request)) *Route {
+	return m.Handle(pattern, http.HandlerFunc(handlerFunc))
 }
 
-func (m *Mux) HandleFunc(pattern string, handlerFunc func(http.ResponseWriter, *http.Request)) {
-	m.Handle(pattern, http.HandlerFunc(handlerFunc))
+// HandleMatch registers a handler for "pattern" which is served only when all of the "matchers" are passing,
+// i.e `mux.HandleMatch("/users", v2Handler, muxie.Header("X-API-Version", "2"))`.
+// Same pattern can be registered many times, the handlers are checked in order of registration
+// and the one registered through `Handle` (if any) is used as the fallback when none of them matches.
+func (m *Mux) HandleMatch(pattern string, handler http.Handler, matchers ...Matcher) {
+	m.Routes.insertNode(m.root+pattern, WithMatch(handler, matchers...), withMux(m))
 }
 
 func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
+	// the group which its settings are used to serve this request.
+	g := m.groupFor(path)
 
-	if m.PathCorrection {
+	if g.pathCorrection() {
 		if len(path) > 1 && strings.HasSuffix(path, "/") {
 			// Remove trailing slash and client-permanent rule for redirection,
 			// if confgiuration allows that and path has an extra slash.
@@ -79,20 +149,148 @@ func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	pw := m.paramsPool.Get().(*paramsWriter)
 	pw.reset(w)
-	n := m.Routes.Search(path, pw)
-	if n != nil && n.Handler != nil {
-		n.Handler.ServeHTTP(pw, r)
-	}
+
+	parent, _ := w.(*paramsWriter)
+	m.dispatch(g, pw, parent, r, path)
 
 	m.paramsPool.Put(pw)
 }
 
+// dispatch searches the route of the "path" and serves it, "g" is the group of the path
+// and "parent" is the params writer of a parent mux, if served by one (see `Mount`).
+func (m *Mux) dispatch(g *Mux, pw *paramsWriter, parent *paramsWriter, r *http.Request, path string) {
+	hooks := m.hooks()
+	var start time.Time
+	if hooks != nil {
+		start = time.Now()
+	}
+
+	var n *Node
+	// a group serves only the routes under its prefix.
+	if m.parent == nil || hasPathPrefix(path, m.root) {
+		n = m.Routes.Search(path, pw)
+	}
+
+	if parent != nil {
+		// served by a parent mux, i.e through `Mount`,
+		// inherit its parameters, ours have priority.
+		pw.params = append(pw.params, parent.params...)
+	}
+
+	var h http.Handler
+	if n != nil {
+		if n.mux != nil {
+			g = n.mux
+		}
+		h = g.handlerFor(n, pw, r)
+		if h != nil {
+			pw.node = n
+			if m.routeContext() {
+				r = withRouteContext(r, n)
+			}
+		}
+	}
+
+	var e *RouteEvent
+	if hooks != nil {
+		e = &RouteEvent{Request: r, Params: pw.params, Lookup: time.Since(start)}
+		if h != nil {
+			e.Node = n
+			hooks.OnMatch(e)
+		} else {
+			hooks.OnNotFound(e)
+		}
+		r = e.Request
+	}
+
+	pw.group = g
+	if metrics := m.metrics(); metrics != nil {
+		metrics.serve(g, h, n, pw, r)
+	} else {
+		g.serve(h, n, pw, r)
+	}
+
+	if e != nil {
+		e.Request = r
+		e.Params = pw.params
+		e.Status = pw.statusCode()
+		e.Elapsed = time.Since(start)
+		hooks.OnServeDone(e)
+	}
+}
+
+// serve calls the route's handler "h" with the configured recovery mode
+// or the not found handler if "h" is nil.
+func (m *Mux) serve(h http.Handler, n *Node, pw *paramsWriter, r *http.Request) {
+	if m.Maintenance() {
+		m.serveError(pw, r, http.StatusServiceUnavailable)
+		return
+	}
+
+	if h == nil {
+		m.serveError(pw, r, http.StatusNotFound)
+		return
+	}
+
+	if cors := m.cors(); cors != nil && cors.handle(pw, r, n) {
+		return
+	}
+
+	if r.Method == http.MethodOptions {
+		// answer with the methods of the whole node,
+		// not only the ones of the selected `MethodHandler`, if any.
+		if mh, ok := h.(*MethodHandler); ok && mh.handlers[http.MethodOptions] == nil {
+			pw.Header().Set("Allow", strings.Join(n.Methods(), ", "))
+			pw.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	var valid bool
+	if r, valid = validateBody(n, h, pw, r); !valid {
+		return
+	}
+
+	if m.recoverEnabled() {
+		m.serveRecover(h, n, pw, r)
+		return
+	}
+
+	serveHandler(h, pw, r)
+}
+
+// SubMux is a group of routes under a common path prefix, see `Mux#Of`.
+// It can be served directly as well.
 type SubMux interface {
-	Handle(pattern string, handler http.Handler)
-	HandleFunc(pattern string, handlerFunc func(http.ResponseWriter, *http.Request))
+	http.Handler
+	Handle(pattern string, handler http.Handler) *Route
+	HandleFunc(pattern string, handlerFunc func(http.ResponseWriter, *http.Request)) *Route
+	HandleFuncE(pattern string, handlerFunc func(http.ResponseWriter, *http.Request) error) *Route
+	Route(name string) *Route
+	HandleMatch(pattern string, handler http.Handler, matchers ...Matcher)
+	HandleVersion(pattern, version string, handler http.Handler)
+	Mount(prefix string, handler http.Handler)
+	Controller(prefix string, ctrl interface{}) []string
 	Of(prefix string) SubMux
+
+	Prefix() string
+	SetPathCorrection(enable bool)
+	SetRecover(enable bool)
+	SetNotFoundHandler(handler http.Handler)
+	SetPanicHandler(handler http.Handler)
+	SetCORS(cors *CORS)
+	HandleError(statusCode int, handler ErrorHandler)
+	HandleErrorFunc(statusCode int, handlerFunc func(http.ResponseWriter, *http.Request, int))
+	SetErrorResponder(responder ErrorResponder)
+	SetMaintenance(enable bool)
+	Maintenance() bool
+	SetVersioning(versioning *Versioning)
+	SetMeta(key string, value interface{})
+	Meta(key string) interface{}
 }
 
+// Of returns a group of routes under the "prefix",
+// same "prefix" returns the same group.
 func (m *Mux) Of(prefix string) SubMux {
 	if prefix == "" || prefix == pathSep {
 		return m
@@ -115,8 +313,194 @@ func (m *Mux) Of(prefix string) SubMux {
 	// remove any duplication of slashes "/".
 	prefix = pathSep + strings.Trim(m.root+prefix, pathSep)
 
-	return &Mux{
-		Routes: m.Routes,
-		root:   prefix,
+	// same full prefix returns the same group, no matter how it was built,
+	// i.e Of("/api/v1") and Of("/api").Of("/v1").
+	parent := m.top().groupFor(prefix)
+	if parent.root == prefix {
+		return parent
+	}
+
+	child := &Mux{
+		Routes:     m.Routes,
+		paramsPool: m.paramsPool,
+		root:       prefix,
+		parent:     parent,
+	}
+
+	// attach the existing groups under the new prefix to it, i.e Of("/api/v1") and then Of("/api").
+	children := parent.children[:0]
+	for _, c := range parent.children {
+		if hasPathPrefix(c.root, prefix) {
+			c.parent = child
+			child.children = append(child.children, c)
+			continue
+		}
+
+		children = append(children, c)
 	}
+	parent.children = append(children, child)
+
+	return child
+}
+
+// Prefix returns the full path prefix of the group, it's empty for the root `Mux`.
+func (m *Mux) Prefix() string {
+	return m.root
+}
+
+// SetPathCorrection overrides the parent's `PathCorrection` setting for this group.
+func (m *Mux) SetPathCorrection(enable bool) {
+	m.PathCorrection = enable
+	m.pathCorrectionSet = true
+}
+
+// SetRecover overrides the parent's `Recover` setting for this group.
+func (m *Mux) SetRecover(enable bool) {
+	m.Recover = enable
+	m.recoverSet = true
+}
+
+// SetNotFoundHandler sets the `NotFoundHandler` of this group.
+func (m *Mux) SetNotFoundHandler(handler http.Handler) {
+	m.NotFoundHandler = handler
+}
+
+// SetPanicHandler sets the `PanicHandler` of this group.
+func (m *Mux) SetPanicHandler(handler http.Handler) {
+	m.PanicHandler = handler
+}
+
+// SetCORS sets the `CORS` settings of this group.
+func (m *Mux) SetCORS(cors *CORS) {
+	m.CORS = cors
+}
+
+// SetVersioning sets the `Versioning` settings of this group.
+func (m *Mux) SetVersioning(versioning *Versioning) {
+	m.Versioning = versioning
+}
+
+// SetMeta stores a metadata value to this group, i.e a group's description.
+func (m *Mux) SetMeta(key string, value interface{}) {
+	if m.meta == nil {
+		m.meta = make(map[string]interface{})
+	}
+
+	m.meta[key] = value
+}
+
+// Meta returns a metadata value of this group or of its closest parent that has it.
+func (m *Mux) Meta(key string) interface{} {
+	for g := m; g != nil; g = g.parent {
+		if v, ok := g.meta[key]; ok {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// groupFor returns the deepest group, starting from "m", that the "path" belongs to.
+func (m *Mux) groupFor(path string) *Mux {
+	g := m
+	for len(g.children) > 0 {
+		var closest *Mux
+		for _, child := range g.children {
+			if hasPathPrefix(path, child.root) && (closest == nil || len(child.root) > len(closest.root)) {
+				closest = child
+			}
+		}
+
+		if closest == nil {
+			break
+		}
+
+		g = closest
+	}
+
+	return g
+}
+
+func hasPathPrefix(path, prefix string) bool {
+	return strings.HasPrefix(path, prefix) && (len(path) == len(prefix) || path[len(prefix)] == pathSepB)
+}
+
+// the below return the settings of the group, inherited by the parents.
+
+func (m *Mux) pathCorrection() bool {
+	g := m
+	for !g.PathCorrection && !g.pathCorrectionSet && g.parent != nil {
+		g = g.parent
+	}
+
+	return g.PathCorrection
+}
+
+func (m *Mux) recoverEnabled() bool {
+	g := m
+	for !g.Recover && !g.recoverSet && g.parent != nil {
+		g = g.parent
+	}
+
+	return g.Recover
+}
+
+func (m *Mux) onPanic() func(PanicInfo) {
+	for g := m; g != nil; g = g.parent {
+		if g.OnPanic != nil {
+			return g.OnPanic
+		}
+	}
+
+	return nil
+}
+
+func (m *Mux) cors() *CORS {
+	for g := m; g != nil; g = g.parent {
+		if g.CORS != nil {
+			return g.CORS
+		}
+	}
+
+	return nil
+}
+
+func (m *Mux) versioning() *Versioning {
+	for g := m; g != nil; g = g.parent {
+		if g.Versioning != nil {
+			return g.Versioning
+		}
+	}
+
+	return DefaultVersioning
+}
+
+func (m *Mux) metrics() *Metrics {
+	for g := m; g != nil; g = g.parent {
+		if g.Metrics != nil {
+			return g.Metrics
+		}
+	}
+
+	return nil
+}
+
+func (m *Mux) routeContext() bool {
+	for g := m; g != nil; g = g.parent {
+		if g.RouteContext {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *Mux) hooks() Hooks {
+	for g := m; g != nil; g = g.parent {
+		if g.Hooks != nil {
+			return g.Hooks
+		}
+	}
+
+	return nil
 }
@@ -95,3 +95,242 @@ func TestMuxOf(t *testing.T) {
 		t.Fatalf("expected to receive '%s' but got '%s'", expected, got)
 	}
 }
+
+func TestMuxRecover(t *testing.T) {
+	var info PanicInfo
+
+	mux := NewMux()
+	mux.Recover = true
+	mux.OnPanic = func(i PanicInfo) {
+		info = i
+	}
+
+	mux.Routes.Insert("/users/:id", WithTag("user"), WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("something bad")
+	})))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/users/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if expected, got := http.StatusInternalServerError, res.StatusCode; expected != got {
+		t.Fatalf("expected status code: %d but got %d", expected, got)
+	}
+
+	if expected, got := "/users/:id", info.Pattern; expected != got {
+		t.Fatalf("expected pattern: '%s' but got '%s'", expected, got)
+	}
+
+	if expected, got := "user", info.Tag; expected != got {
+		t.Fatalf("expected tag: '%s' but got '%s'", expected, got)
+	}
+
+	if len(info.Params) != 1 || info.Params[0].Value != "42" {
+		t.Fatalf("expected param id=42 but got %v", info.Params)
+	}
+
+	if expected, got := "something bad", info.Value; expected != got {
+		t.Fatalf("expected panic value: '%v' but got '%v'", expected, got)
+	}
+
+	if len(info.Stack) == 0 {
+		t.Fatalf("expected stack trace")
+	}
+
+	mux.PanicHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "recovered %s", GetParam(w, "id"))
+	})
+
+	res, err = http.Get(srv.URL + "/users/43")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := http.StatusServiceUnavailable, res.StatusCode; expected != got {
+		t.Fatalf("expected status code: %d but got %d", expected, got)
+	}
+
+	if expected, got := "recovered 43", string(body); expected != got {
+		t.Fatalf("expected to receive '%s' but got '%s'", expected, got)
+	}
+}
+
+func TestMuxOfSettings(t *testing.T) {
+	mux := NewMux()
+	mux.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "root not found")
+	})
+	mux.PathCorrection = true
+
+	v1 := mux.Of("/v1")
+	v1.SetMeta("description", "version 1")
+	v1.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello v1")
+	})
+
+	api := mux.Of("/api")
+	api.SetPathCorrection(false)
+	api.SetRecover(true)
+	api.SetNotFoundHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"not found"}`)
+	}))
+	api.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello api")
+	})
+	api.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("api panic")
+	})
+
+	users := api.Of("/users")
+	users.HandleFunc("/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "user %s", GetParam(w, "id"))
+	})
+
+	if expected, got := "/api/users", users.Prefix(); expected != got {
+		t.Fatalf("expected prefix: '%s' but got '%s'", expected, got)
+	}
+
+	if expected, got := "version 1", v1.Of("/nested").Meta("description"); expected != got {
+		t.Fatalf("expected inherited meta: '%v' but got '%v'", expected, got)
+	}
+
+	if api != mux.Of("/api/") {
+		t.Fatalf("expected same group for the same prefix")
+	}
+
+	tests := []struct {
+		handler    http.Handler
+		path       string
+		statusCode int
+		expected   string
+	}{
+		{mux, "/v1/hello", http.StatusOK, "Hello v1"},
+		{mux, "/v1/hello/", http.StatusMovedPermanently, ""},
+		{mux, "/v1/other", http.StatusNotFound, "root not found"},
+		{mux, "/api/hello", http.StatusOK, "Hello api"},
+		// path correction is disabled for that group.
+		{mux, "/api/hello/", http.StatusNotFound, `{"error":"not found"}`},
+		{mux, "/api/other", http.StatusNotFound, `{"error":"not found"}`},
+		{mux, "/api/users/42", http.StatusOK, "user 42"},
+		{mux, "/api/users/42/other", http.StatusNotFound, `{"error":"not found"}`},
+		{mux, "/api/panic", http.StatusInternalServerError, "Internal Server Error\n"},
+		// serve groups directly.
+		{v1, "/v1/hello", http.StatusOK, "Hello v1"},
+		{users, "/api/users/42", http.StatusOK, "user 42"},
+		{users, "/v1/hello", http.StatusNotFound, `{"error":"not found"}`},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s: expected status code: %d but got %d", i, tt.path, expected, got)
+		}
+
+		if tt.statusCode == http.StatusMovedPermanently {
+			continue
+		}
+
+		if expected, got := tt.expected, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
+
+func TestMuxOfSamePrefix(t *testing.T) {
+	mux := NewMux()
+
+	v1 := mux.Of("/api/v1")
+	v1.SetMeta("description", "version 1")
+	v1.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello v1")
+	})
+
+	api := mux.Of("/api")
+	api.SetNotFoundHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "api not found")
+	}))
+
+	if v1 != api.Of("/v1") {
+		t.Fatalf("expected same group for the same full prefix")
+	}
+
+	if expected, got := "version 1", api.Of("/v1").Meta("description"); expected != got {
+		t.Fatalf("expected meta: '%v' but got '%v'", expected, got)
+	}
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"/api/v1/hello", http.StatusOK, "Hello v1"},
+		// the nested group inherits the group attached later.
+		{"/api/v1/missing", http.StatusNotFound, "api not found"},
+	}
+
+	for i, tt := range tests {
+		res, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, got := tt.expectedStatus, res.StatusCode; expected != got {
+			t.Fatalf("[%d] %s: expected status code: %d but got %d", i, tt.path, expected, got)
+		}
+
+		if expected, got := tt.expectedBody, string(body); expected != got {
+			t.Fatalf("[%d] %s: expected body: '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
+
+func TestMuxHandleSamePattern(t *testing.T) {
+	mux := NewMux()
+	mux.HandleFunc("/x", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "first") })
+	route := mux.HandleFunc("/x", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "second") })
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/x", nil))
+	if expected, got := "second", rec.Body.String(); expected != got {
+		t.Fatalf("expected to receive '%s' but got '%s'", expected, got)
+	}
+
+	rec = httptest.NewRecorder()
+	route.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/x", nil))
+	if expected, got := "second", rec.Body.String(); expected != got {
+		t.Fatalf("expected route's handler to respond with '%s' but got '%s'", expected, got)
+	}
+
+	// the trie's insert resets the values of an already registered node.
+	tree := NewTrie()
+	tree.Insert("/x", WithTag("x"), WithData("data"), WithHandler(http.NotFoundHandler()))
+	tree.InsertRoute("/x", "", nil)
+	if n := tree.Search("/x", nil); n.Tag != "" || n.Data != nil || n.Handler != nil {
+		t.Fatalf("expected the node's values to be reset but got '%s', '%v', '%v'", n.Tag, n.Data, n.Handler)
+	}
+}
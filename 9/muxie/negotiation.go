@@ -0,0 +1,208 @@
+package muxie
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentHandler routes a request to a handler based on the media type
+// that it produces (the request's "Accept") and consumes (the request's "Content-Type"),
+// it is registered as the handler of a route, i.e
+//
+//	mux.Handle("/reports/:id", muxie.Negotiate().
+//		Produces("application/json", jsonReport).
+//		Produces("text/csv", csvReport).
+//		Produces("text/html", htmlReport))
+//
+// The best match is picked based on the "Accept" quality values and wildcards,
+// in a tie the first registered wins, and if a request has no "Accept" header then the first registered is picked.
+// Requests with a "Content-Type" that no handler consumes are answered with 415 Unsupported Media Type
+// and requests that no handler produces an acceptable media type for with 406 Not Acceptable.
+// The response's "Content-Type" is set to the produced media type before the handler is called.
+type ContentHandler struct {
+	entries []*contentEntry
+}
+
+type contentEntry struct {
+	produces string   // empty for any.
+	consumes []string // empty for any.
+	handler  http.Handler
+}
+
+// Negotiate returns a new, empty, `ContentHandler`.
+func Negotiate() *ContentHandler {
+	return new(ContentHandler)
+}
+
+// Handle registers a "handler" which produces the "produces" media type (empty for any)
+// and consumes the "consumes" media types (empty for any), i.e "application/json" or "application/*".
+func (c *ContentHandler) Handle(produces string, consumes []string, handler http.Handler) *ContentHandler {
+	lowerConsumes := make([]string, len(consumes))
+	for i := range consumes {
+		lowerConsumes[i] = strings.ToLower(consumes[i])
+	}
+
+	c.entries = append(c.entries, &contentEntry{
+		produces: strings.ToLower(produces),
+		consumes: lowerConsumes,
+		handler:  handler,
+	})
+	return c
+}
+
+// Produces registers a "handler" which produces the "mediaType" and consumes any.
+func (c *ContentHandler) Produces(mediaType string, handler http.Handler) *ContentHandler {
+	return c.Handle(mediaType, nil, handler)
+}
+
+// Consumes registers a "handler" which consumes the "mediaType" and produces any.
+func (c *ContentHandler) Consumes(mediaType string, handler http.Handler) *ContentHandler {
+	return c.Handle("", []string{mediaType}, handler)
+}
+
+func (c *ContentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	candidates := c.entries
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			mediaType = ""
+		}
+
+		candidates = make([]*contentEntry, 0, len(c.entries))
+		for _, e := range c.entries {
+			if len(e.consumes) == 0 {
+				candidates = append(candidates, e)
+				continue
+			}
+
+			for _, consumes := range e.consumes {
+				if mediaType != "" && matchMediaType(consumes, mediaType) {
+					candidates = append(candidates, e)
+					break
+				}
+			}
+		}
+
+		if len(candidates) == 0 {
+			Error(w, r, http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+
+	w.Header().Add("Vary", "Accept")
+
+	e := bestContentEntry(candidates, parseAccept(r.Header.Values("Accept")))
+	if e == nil {
+		Error(w, r, http.StatusNotAcceptable)
+		return
+	}
+
+	if e.produces != "" && !strings.Contains(e.produces, "*") {
+		w.Header().Set("Content-Type", e.produces)
+	}
+
+	e.handler.ServeHTTP(w, r)
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses the values of the "Accept" header, it returns nil if there is no "Accept".
+func parseAccept(values []string) []acceptRange {
+	var ranges []acceptRange
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			params := strings.Split(part, ";")
+			mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+			if mediaType == "" {
+				continue
+			}
+
+			q := 1.0
+			for _, param := range params[1:] {
+				if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.TrimSpace(key) == "q" {
+					if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+						q = f
+					}
+				}
+			}
+
+			ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+		}
+	}
+
+	return ranges
+}
+
+// bestContentEntry returns the candidate which produces the media type with the highest quality.
+func bestContentEntry(candidates []*contentEntry, ranges []acceptRange) *contentEntry {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if len(ranges) == 0 {
+		return candidates[0]
+	}
+
+	var (
+		best            *contentEntry
+		bestQ           float64
+		bestSpecificity = -1
+	)
+
+	for _, e := range candidates {
+		produces := e.produces
+		if produces == "" {
+			produces = "*/*"
+		}
+
+		// the quality of the most specific range that matches.
+		q, specificity := 0.0, -1
+		for _, ar := range ranges {
+			if !matchMediaType(ar.mediaType, produces) && !matchMediaType(produces, ar.mediaType) {
+				continue
+			}
+
+			if s := mediaTypeSpecificity(ar.mediaType); s > specificity {
+				q, specificity = ar.q, s
+			}
+		}
+
+		if specificity == -1 || q <= 0 {
+			continue
+		}
+
+		if q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = e, q, specificity
+		}
+	}
+
+	return best
+}
+
+// matchMediaType reports whether the "mediaType" matches the "pattern", which may contain wildcards,
+// i.e "application/*" matches "application/json".
+func matchMediaType(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == "*" || pattern == mediaType {
+		return true
+	}
+
+	typ, subtype, _ := strings.Cut(pattern, "/")
+	mtyp, msubtype, _ := strings.Cut(mediaType, "/")
+	return typ == mtyp && (subtype == "*" || subtype == msubtype)
+}
+
+func mediaTypeSpecificity(mediaType string) int {
+	switch {
+	case mediaType == "*/*" || mediaType == "*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
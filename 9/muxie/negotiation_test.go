@@ -0,0 +1,76 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContentHandler(t *testing.T) {
+	writeText := func(text string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s:%s", text, GetParam(w, "id"))
+		}
+	}
+
+	mux := NewMux()
+	mux.Handle("/reports/:id", Negotiate().
+		Produces("application/json", writeText("json")).
+		Produces("text/csv", writeText("csv")).
+		Produces("text/html", writeText("html")))
+	mux.Handle("/reports", Negotiate().
+		Handle("application/json", []string{"application/json"}, writeText("json_from_json")).
+		Handle("application/json", []string{"application/x-www-form-urlencoded", "multipart/*"}, writeText("json_from_form")))
+
+	tests := []struct {
+		method      string
+		path        string
+		header      http.Header
+		statusCode  int
+		expected    string
+		contentType string
+	}{
+		{http.MethodGet, "/reports/1", nil, http.StatusOK, "json:1", "application/json"},
+		{http.MethodGet, "/reports/1", http.Header{"Accept": {"text/csv"}}, http.StatusOK, "csv:1", "text/csv"},
+		{http.MethodGet, "/reports/1", http.Header{"Accept": {"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"}}, http.StatusOK, "html:1", "text/html"},
+		{http.MethodGet, "/reports/1", http.Header{"Accept": {"text/*;q=0.5, application/json;q=0.4"}}, http.StatusOK, "csv:1", "text/csv"},
+		{http.MethodGet, "/reports/1", http.Header{"Accept": {"text/*;q=0.5, text/html;q=0.6, application/json;q=0.4"}}, http.StatusOK, "html:1", "text/html"},
+		{http.MethodGet, "/reports/1", http.Header{"Accept": {"*/*"}}, http.StatusOK, "json:1", "application/json"},
+		{http.MethodGet, "/reports/1", http.Header{"Accept": {"text/csv;q=0, */*;q=0.1"}}, http.StatusOK, "json:1", "application/json"},
+		{http.MethodGet, "/reports/1", http.Header{"Accept": {"image/png"}}, http.StatusNotAcceptable, "Not Acceptable\n", "text/plain; charset=utf-8"},
+		{http.MethodPost, "/reports", http.Header{"Content-Type": {"application/json; charset=utf-8"}}, http.StatusOK, "json_from_json:", "application/json"},
+		{http.MethodPost, "/reports", http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}, http.StatusOK, "json_from_form:", "application/json"},
+		{http.MethodPost, "/reports", http.Header{"Content-Type": {"multipart/form-data; boundary=x"}}, http.StatusOK, "json_from_form:", "application/json"},
+		{http.MethodPost, "/reports", http.Header{"Content-Type": {"text/xml"}}, http.StatusUnsupportedMediaType, "Unsupported Media Type\n", "text/plain; charset=utf-8"},
+	}
+
+	for i, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(""))
+		req.Header = tt.header
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s: expected status code: %d but got %d", i, tt.path, expected, got)
+		}
+
+		if expected, got := tt.expected, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+
+		if expected, got := tt.contentType, rec.Header().Get("Content-Type"); expected != got {
+			t.Fatalf("[%d] %s: expected content type: '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
+
+func TestContentHandlerEmpty(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Negotiate().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if expected, got := http.StatusNotAcceptable, rec.Code; expected != got {
+		t.Fatalf("expected status code: %d but got %d", expected, got)
+	}
+}
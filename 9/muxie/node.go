@@ -2,6 +2,7 @@ package muxie
 
 import (
 	"net/http"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -13,6 +14,9 @@ type Node struct {
 	hasDynamicChild        bool // does one of the children contains a parameter or wildcard?
 	childNamedParameter    bool // is the child a named parameter (single segmnet)
 	childWildcardParameter bool // or it is a wildcard (can be more than one path segments) ?
+	// the regexp constrained named parameters children, in order of registration, i.e :year([0-9]{4}).
+	regexpChildren []*Node
+	re             *regexp.Regexp // the compiled regexp of a regexp constrained named parameter node.
 
 	paramKeys []string // the param keys without : or *.
 	end       bool     // it is a complete node, here we stop and we can say that the node is valid.
@@ -27,6 +31,22 @@ type Node struct {
 
 	// other insert data.
 	Data interface{}
+
+	// handlers with request matchers, evaluated in order of registration
+	// and before the `Handler`, see `WithMatch`.
+	candidates []*candidate
+	// handlers per version, sorted by version, see `WithVersion`.
+	versions []*versionedHandler
+
+	// the group that registered this node, if any.
+	mux *Mux
+	// metadata and description set through the `Route`.
+	meta        map[string]interface{}
+	description string
+	// the limit of the request bodies validated against a `Schema`, zero for the default one.
+	maxBodySize int64
+	// the schemas per method, the empty key is for any method, see `Route#Schema`.
+	schemas map[string]*Schema
 }
 
 func NewNode() *Node {
@@ -59,11 +79,46 @@ func (n *Node) hasChild(s string) bool {
 	return n.getChild(s) != nil
 }
 
+// handlerFor returns the handler of the first candidate that its matchers are passing,
+// if none then it falls back to the node's `Handler`, which can be nil.
+func (n *Node) handlerFor(r *http.Request) http.Handler {
+	for _, c := range n.candidates {
+		if c.match(r) {
+			return c.handler
+		}
+	}
+
+	return n.Handler
+}
+
+// findRegexpChild returns the first regexp constrained named parameter child that matches the "segment".
+// It's small enough to be inlined, so the nodes without regexp children pay nothing.
+func (n *Node) findRegexpChild(segment string) *Node {
+	if len(n.regexpChildren) == 0 {
+		return nil
+	}
+
+	return n.matchRegexpChild(segment)
+}
+
+func (n *Node) matchRegexpChild(segment string) *Node {
+	for _, child := range n.regexpChildren {
+		if child.re.MatchString(segment) {
+			return child
+		}
+	}
+
+	return nil
+}
+
 func (n *Node) findClosestParentWildcardNode() *Node {
 	n = n.parent
 	for n != nil {
+		// a mid-pattern wildcard, i.e /files/*path/edit, can't be a fallback.
 		if n.childWildcardParameter {
-			return n.getChild(WildcardParamStart)
+			if wild := n.getChild(WildcardParamStart); wild.end {
+				return wild
+			}
 		}
 
 		n = n.parent
@@ -109,6 +164,35 @@ func (n *Node) String() string {
 	return n.key
 }
 
+// Pattern returns the registered pattern of the node, i.e "/users/:id".
+func (n *Node) Pattern() string {
+	return n.key
+}
+
+// ParamKeys returns the parameter names of the node's pattern, without the : or *, in order.
+func (n *Node) ParamKeys() []string {
+	return n.paramKeys
+}
+
+// Group returns the group (or the `Mux`) that registered the node,
+// it's nil if the node was inserted to the trie directly.
+func (n *Node) Group() SubMux {
+	if n.mux == nil {
+		return nil
+	}
+
+	return n.mux
+}
+
+// Prefix returns the path prefix of the group that registered the node, see `Group`.
+func (n *Node) Prefix() string {
+	if n.mux == nil {
+		return ""
+	}
+
+	return n.mux.root
+}
+
 func (n *Node) IsEnd() bool {
 	return n.end
 }
@@ -0,0 +1,199 @@
+package muxie
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Params is the list of the parameters of the matched route, in order, see `GetParams`.
+type Params []ParamEntry
+
+// ErrParamNotFound is the error of a `ParamError` when the parameter does not exist.
+var ErrParamNotFound = errors.New("not found")
+
+// ParamError is returned by the typed accessors of the `Params` and its `Bind`
+// when a parameter is missing or its value can't be converted.
+type ParamError struct {
+	Key   string
+	Value string
+	Type  string // the requested type, i.e "int".
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	if e.Err == ErrParamNotFound {
+		return fmt.Sprintf("muxie: parameter '%s' not found", e.Key)
+	}
+
+	return fmt.Sprintf("muxie: parameter '%s': '%s' is not a valid %s", e.Key, e.Value, e.Type)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// Len returns the number of the parameters.
+func (p Params) Len() int {
+	return len(p)
+}
+
+// Lookup returns the value of the "key" parameter and reports whether it exists,
+// unlike `Get` which returns "" for both missing and empty values.
+func (p Params) Lookup(key string) (string, bool) {
+	for i := range p {
+		if p[i].Key == key {
+			return p[i].Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Get returns the value of the "key" parameter, if any.
+func (p Params) Get(key string) string {
+	value, _ := p.Lookup(key)
+	return value
+}
+
+// Range calls the "fn" for each parameter, in order, until it returns false.
+func (p Params) Range(fn func(key, value string) bool) {
+	for i := range p {
+		if !fn(p[i].Key, p[i].Value) {
+			return
+		}
+	}
+}
+
+func (p Params) lookup(key, typ string) (string, error) {
+	value, ok := p.Lookup(key)
+	if !ok {
+		return "", &ParamError{Key: key, Type: typ, Err: ErrParamNotFound}
+	}
+
+	return value, nil
+}
+
+// Int returns the value of the "key" parameter as int.
+func (p Params) Int(key string) (int, error) {
+	value, err := p.lookup(key, "int")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ParamError{Key: key, Value: value, Type: "int", Err: err}
+	}
+
+	return n, nil
+}
+
+// Int64 returns the value of the "key" parameter as int64.
+func (p Params) Int64(key string) (int64, error) {
+	value, err := p.lookup(key, "int64")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &ParamError{Key: key, Value: value, Type: "int64", Err: err}
+	}
+
+	return n, nil
+}
+
+// Bool returns the value of the "key" parameter as bool, see `strconv.ParseBool`.
+func (p Params) Bool(key string) (bool, error) {
+	value, err := p.lookup(key, "bool")
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &ParamError{Key: key, Value: value, Type: "bool", Err: err}
+	}
+
+	return b, nil
+}
+
+// UUID returns the value of the "key" parameter as the 16 bytes of a UUID,
+// in its canonical form, i.e "f47ac10b-58cc-4372-a567-0e02b2c3d479".
+func (p Params) UUID(key string) ([16]byte, error) {
+	var uuid [16]byte
+	value, err := p.lookup(key, "uuid")
+	if err != nil {
+		return uuid, err
+	}
+
+	if !parseUUID(value, &uuid) {
+		return uuid, &ParamError{Key: key, Value: value, Type: "uuid", Err: errors.New("invalid uuid")}
+	}
+
+	return uuid, nil
+}
+
+func parseUUID(s string, uuid *[16]byte) bool {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return false
+	}
+
+	b := make([]byte, 0, 32)
+	b = append(b, s[:8]...)
+	b = append(b, s[9:13]...)
+	b = append(b, s[14:18]...)
+	b = append(b, s[19:23]...)
+	b = append(b, s[24:]...)
+
+	_, err := hex.Decode(uuid[:], b)
+	return err == nil
+}
+
+// Bind fills the fields of the struct that "ptr" points to,
+// which are tagged with a `param:"key"`, from the parameters, i.e
+//
+//	var args struct {
+//		ID   int64  `param:"id"`
+//		Slug string `param:"slug"`
+//	}
+//	err := muxie.GetParams(w).Bind(&args)
+//
+// The fields can be strings, bools, ints, uints and floats, missing parameters are skipped.
+func (p Params) Bind(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("muxie: bind: expected a pointer to a struct but got %T", ptr)
+	}
+
+	v = v.Elem()
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		key, ok := field.Tag.Lookup("param")
+		if !ok || key == "-" || field.PkgPath != "" { // not tagged or unexported.
+			continue
+		}
+
+		if !isParamKind(field.Type.Kind()) {
+			return fmt.Errorf("muxie: bind: unsupported type %s of field %s", field.Type, field.Name)
+		}
+
+		value, ok := p.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		fieldValue, err := convertParam(value, field.Type)
+		if err != nil {
+			return &ParamError{Key: key, Value: value, Type: field.Type.Kind().String(), Err: err}
+		}
+
+		v.Field(i).Set(fieldValue)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package muxie
+
+import (
+	"context"
+	"net/http"
+)
+
+// ParamsHandler is a handler which receives the route's parameters explicitly.
+// The `Mux` detects it and calls its `ServeHTTPParams` instead of the `ServeHTTP`,
+// so there is no need for the `GetParam` lookup through the response writer.
+//
+// It can be registered through the `ParamsHandlerFunc` or the `ToHandler` adapters.
+type ParamsHandler interface {
+	ServeHTTPParams(w http.ResponseWriter, r *http.Request, params Params)
+}
+
+// ParamsHandlerFunc is the function form of the `ParamsHandler`,
+// it's an `http.Handler` too, i.e
+//
+//	mux.Handle("/users/:id", muxie.ParamsHandlerFunc(func(w http.ResponseWriter, r *http.Request, params muxie.Params) {...}))
+type ParamsHandlerFunc func(w http.ResponseWriter, r *http.Request, params Params)
+
+// ServeHTTPParams calls f(w, r, params).
+func (f ParamsHandlerFunc) ServeHTTPParams(w http.ResponseWriter, r *http.Request, params Params) {
+	f(w, r, params)
+}
+
+// ServeHTTP calls f(w, r, GetParams(w)), it's used when
+// the handler is not served by the `Mux` directly, i.e through a middleware.
+// If the "w" is wrapped then the parameters are taken from the request's context, see `ParamsToContext`.
+func (f ParamsHandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := GetParams(w)
+	if params == nil {
+		params = GetRequestParams(r)
+	}
+
+	f(w, r, params)
+}
+
+type paramsContextKey struct{}
+
+// ParamsToContext stores the route's parameters to the request's context before calling the "next",
+// so a `ParamsHandler` behind middlewares that wrap the response writer still receives them, i.e
+//
+//	mux.Handle("/users/:id", muxie.ParamsToContext(gzip(muxie.ParamsHandlerFunc(...))))
+//
+// The `Route#Use` does it automatically for `ParamsHandler` routes.
+func ParamsToContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pw, ok := w.(*paramsWriter); ok {
+			r = r.WithContext(context.WithValue(r.Context(), paramsContextKey{}, pw.params))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetRequestParams returns the route's parameters stored to the request's context by the `ParamsToContext`.
+func GetRequestParams(r *http.Request) Params {
+	params, _ := r.Context().Value(paramsContextKey{}).(Params)
+	return params
+}
+
+// ToHandler adapts a `ParamsHandler` to an `http.Handler`.
+func ToHandler(h ParamsHandler) http.Handler {
+	if handler, ok := h.(http.Handler); ok {
+		return handler
+	}
+
+	return ParamsHandlerFunc(h.ServeHTTPParams)
+}
+
+// serveHandler calls the `ServeHTTPParams` of "h" if it's a `ParamsHandler`, otherwise its `ServeHTTP`.
+func serveHandler(h http.Handler, pw *paramsWriter, r *http.Request) {
+	if ph, ok := h.(ParamsHandler); ok {
+		ph.ServeHTTPParams(pw, r, pw.params)
+		return
+	}
+
+	h.ServeHTTP(pw, r)
+}
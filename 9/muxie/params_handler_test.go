@@ -0,0 +1,54 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type greetHandler struct{}
+
+func (greetHandler) ServeHTTPParams(w http.ResponseWriter, r *http.Request, params Params) {
+	fmt.Fprintf(w, "Hello %s", params[0].Value)
+}
+
+func TestParamsHandler(t *testing.T) {
+	mux := NewMux()
+	mux.Handle("/hello/:name", ToHandler(greetHandler{}))
+	mux.Handle("/users/:id/:action", ParamsHandlerFunc(func(w http.ResponseWriter, r *http.Request, params Params) {
+		fmt.Fprintf(w, "%d params: %s %s", len(params), params[0].Value, params[1].Value)
+	}))
+	// through middlewares which wrap the response writer.
+	wrap := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(struct{ http.ResponseWriter }{w}, r)
+		})
+	}
+	countParams := ParamsHandlerFunc(func(w http.ResponseWriter, r *http.Request, params Params) {
+		fmt.Fprintf(w, "%d params: %s", len(params), params.Get("name"))
+	})
+	mux.Handle("/wrapped/:name", countParams).Use(wrap)
+	mux.Handle("/manual/:name", ParamsToContext(wrap(countParams)))
+	mux.Handle("/methods/:name", Methods().Handle(http.MethodGet, ToHandler(greetHandler{})))
+
+	tests := []struct {
+		path string
+		body string
+	}{
+		{"/hello/kataras", "Hello kataras"},
+		{"/users/42/edit", "2 params: 42 edit"},
+		{"/wrapped/kataras", "1 params: kataras"},
+		{"/manual/kataras", "1 params: kataras"},
+		{"/methods/kataras", "Hello kataras"},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if expected, got := tt.body, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
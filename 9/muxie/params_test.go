@@ -0,0 +1,92 @@
+package muxie
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	params := Params{
+		{"id", "42"},
+		{"empty", ""},
+		{"flag", "true"},
+		{"name", "kataras"},
+		{"uuid", "f47ac10b-58cc-4372-a567-0e02b2c3d479"},
+	}
+
+	if expected, got := 5, params.Len(); expected != got {
+		t.Fatalf("expected length: %d but got %d", expected, got)
+	}
+
+	if value, ok := params.Lookup("empty"); !ok || value != "" {
+		t.Fatalf("expected an existing empty parameter but got '%s', %v", value, ok)
+	}
+
+	if _, ok := params.Lookup("missing"); ok {
+		t.Fatalf("expected a missing parameter")
+	}
+
+	if n, err := params.Int("id"); err != nil || n != 42 {
+		t.Fatalf("expected 42 but got %d, %v", n, err)
+	}
+
+	if n, err := params.Int64("id"); err != nil || n != 42 {
+		t.Fatalf("expected 42 but got %d, %v", n, err)
+	}
+
+	if b, err := params.Bool("flag"); err != nil || !b {
+		t.Fatalf("expected true but got %v, %v", b, err)
+	}
+
+	uuid, err := params.UUID("uuid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, got := byte(0xf4), uuid[0]; expected != got {
+		t.Fatalf("expected first uuid byte: %x but got %x", expected, got)
+	}
+
+	_, err = params.Int("name")
+	var paramErr *ParamError
+	if !errors.As(err, &paramErr) || paramErr.Key != "name" || paramErr.Type != "int" {
+		t.Fatalf("expected a param error for 'name' but got %v", err)
+	}
+
+	if _, err = params.Int("missing"); !errors.Is(err, ErrParamNotFound) {
+		t.Fatalf("expected not found error but got %v", err)
+	}
+
+	if _, err = params.UUID("name"); err == nil {
+		t.Fatalf("expected an invalid uuid error")
+	}
+
+	var keys []string
+	params.Range(func(key, value string) bool {
+		keys = append(keys, key)
+		return key != "flag"
+	})
+	if expected, got := 3, len(keys); expected != got {
+		t.Fatalf("expected range to stop after %d parameters but got %d", expected, got)
+	}
+
+	var args struct {
+		ID      int64  `param:"id"`
+		Name    string `param:"name"`
+		Flag    bool   `param:"flag"`
+		Missing string `param:"missing"`
+		Other   string
+	}
+	if err = params.Bind(&args); err != nil {
+		t.Fatal(err)
+	}
+	if args.ID != 42 || args.Name != "kataras" || !args.Flag || args.Missing != "" {
+		t.Fatalf("unexpected bound values: %#v", args)
+	}
+
+	var invalid struct {
+		Name int `param:"name"`
+	}
+	if err = params.Bind(&invalid); !errors.As(err, &paramErr) {
+		t.Fatalf("expected a param error but got %v", err)
+	}
+}
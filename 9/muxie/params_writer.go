@@ -1,6 +1,7 @@
 package muxie
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -12,7 +13,7 @@ func GetParam(w http.ResponseWriter, key string) string {
 	return ""
 }
 
-func GetParams(w http.ResponseWriter) []ParamEntry {
+func GetParams(w http.ResponseWriter) Params {
 	if store, ok := w.(*paramsWriter); ok {
 		return store.params
 	}
@@ -29,9 +30,43 @@ func SetParam(w http.ResponseWriter, key, value string) bool {
 	return false
 }
 
+// GetRoute returns the node of the route that serves the request,
+// its pattern, tag (name), data, parameter names and group are available through it.
+// It returns nil if the "w" is not the one that the `Mux` passed to the handler.
+func GetRoute(w http.ResponseWriter) *Node {
+	if store, ok := w.(*paramsWriter); ok {
+		return store.node
+	}
+
+	return nil
+}
+
+type routeContextKey struct{}
+
+// GetRequestRoute returns the node of the route that serves the request, like `GetRoute` does,
+// but through the request's context, it's available only when the `Mux#RouteContext` is enabled.
+func GetRequestRoute(r *http.Request) *Node {
+	n, _ := r.Context().Value(routeContextKey{}).(*Node)
+	return n
+}
+
+func withRouteContext(r *http.Request, n *Node) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), routeContextKey{}, n))
+}
+
 type paramsWriter struct {
 	http.ResponseWriter
-	params []ParamEntry
+	params Params
+	// the status code written by the handler, zero if nothing written yet.
+	status int
+	// if true then the response body is not written, i.e on HEAD requests served by a GET handler.
+	discardBody bool
+	// the negotiated version of the route, see `GetVersion`.
+	version string
+	// the matched route, see `GetRoute`.
+	node *Node
+	// the group that serves the request, its error handlers are used by `Error`.
+	group *Mux
 }
 
 type ParamEntry struct {
@@ -65,7 +100,51 @@ func (pw *paramsWriter) Get(key string) string {
 	return ""
 }
 
+func (pw *paramsWriter) remove(key string) {
+	for i := 0; i < len(pw.params); i++ {
+		if pw.params[i].Key == key {
+			pw.params = append(pw.params[:i], pw.params[i+1:]...)
+			return
+		}
+	}
+}
+
+func (pw *paramsWriter) WriteHeader(statusCode int) {
+	if pw.status == 0 && statusCode >= 200 { // ignore informational responses.
+		pw.status = statusCode
+	}
+
+	pw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (pw *paramsWriter) Write(b []byte) (int, error) {
+	if pw.status == 0 {
+		pw.status = http.StatusOK
+	}
+
+	if pw.discardBody {
+		return len(b), nil
+	}
+
+	return pw.ResponseWriter.Write(b)
+}
+
+// statusCode returns the written status code, if nothing written yet then
+// it returns 200 because this is what net/http will send at the end.
+func (pw *paramsWriter) statusCode() int {
+	if pw.status == 0 {
+		return http.StatusOK
+	}
+
+	return pw.status
+}
+
 func (pw *paramsWriter) reset(w http.ResponseWriter) {
 	pw.ResponseWriter = w
 	pw.params = pw.params[0:0]
+	pw.status = 0
+	pw.discardBody = false
+	pw.version = ""
+	pw.node = nil
+	pw.group = nil
 }
@@ -0,0 +1,57 @@
+package muxie
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicInfo holds the details of a recovered handler's panic,
+// it is passed to the `Mux#OnPanic` hook.
+type PanicInfo struct {
+	Request *http.Request
+	// Pattern is the registered route's pattern that the request matched, i.e "/users/:id".
+	Pattern string
+	// Tag is the node's tag, i.e the route name.
+	Tag string
+	// Params is a copy of the request's parameters.
+	Params []ParamEntry
+	// Value is the value that the handler panicked with.
+	Value interface{}
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+// serveRecover serves "h" and recovers from its panic, if any,
+// so the caller can still return the params writer back to the pool.
+func (m *Mux) serveRecover(h http.Handler, n *Node, pw *paramsWriter, r *http.Request) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+
+		if rec == http.ErrAbortHandler {
+			// let net/http abort the response as expected,
+			// the writer is not put back to the pool at this case.
+			panic(rec)
+		}
+
+		if onPanic := m.onPanic(); onPanic != nil {
+			params := make([]ParamEntry, len(pw.params))
+			copy(params, pw.params)
+
+			onPanic(PanicInfo{
+				Request: r,
+				Pattern: n.key,
+				Tag:     n.Tag,
+				Params:  params,
+				Value:   rec,
+				Stack:   debug.Stack(),
+			})
+		}
+
+		m.serveStatus(pw, r, http.StatusInternalServerError, true)
+	}()
+
+	serveHandler(h, pw, r)
+}
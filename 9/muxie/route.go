@@ -0,0 +1,174 @@
+package muxie
+
+import (
+	"net/http"
+)
+
+// Wrapper is the type of a middleware, it wraps a handler with another one.
+type Wrapper func(http.Handler) http.Handler
+
+// Route is returned by the `Mux#Handle` and `Mux#HandleFunc`,
+// its chainable methods are applied to the underlying `Node`, i.e
+//
+//	mux.HandleFunc("/users/:id", getUser).
+//		Name("user").
+//		Methods(http.MethodGet).
+//		Use(authMiddleware).
+//		Describe("Returns a user by its id")
+//
+// Note that the `Methods` and `Use` re-install the route's handler to the node.
+type Route struct {
+	node *Node
+	mux  *Mux
+
+	handler  http.Handler // the registered one, without the wrappers.
+	methods  []string
+	wrappers []Wrapper
+	schema   *Schema
+	// the method handler of the node before the `Mux#Handle` replaced it, if any,
+	// so the `Methods` of the same pattern are merged.
+	methodHandler *MethodHandler
+}
+
+// Name sets the name of the route, it's stored as the node's `Tag`,
+// the route can be retrieved later on through `Mux#Route`.
+func (r *Route) Name(name string) *Route {
+	if r.node.Tag != "" {
+		r.mux.top().unregisterName(r.node.Tag, r)
+	}
+
+	r.node.Tag = name
+	r.mux.top().registerName(name, r)
+	return r
+}
+
+// Methods limits the route's handler to the request "methods",
+// a route can be registered many times with different methods, i.e
+//
+//	mux.HandleFunc("/users", listUsers).Methods(http.MethodGet)
+//	mux.HandleFunc("/users", createUser).Methods(http.MethodPost)
+//
+// The node's handler becomes a `MethodHandler`, see there for HEAD, OPTIONS and 405 responses.
+func (r *Route) Methods(methods ...string) *Route {
+	r.methods = append(r.methods, methods...)
+	r.apply()
+	r.applySchema()
+	return r
+}
+
+// Use wraps the route's handler with the "wrappers", the first one is the outer one.
+func (r *Route) Use(wrappers ...Wrapper) *Route {
+	r.wrappers = append(r.wrappers, wrappers...)
+	r.apply()
+	return r
+}
+
+// Meta stores a metadata value to the route's node, see `Node#Meta`.
+func (r *Route) Meta(key string, value interface{}) *Route {
+	if r.node.meta == nil {
+		r.node.meta = make(map[string]interface{})
+	}
+
+	r.node.meta[key] = value
+	return r
+}
+
+// Describe sets a description to the route's node, see `Node#Description`.
+func (r *Route) Describe(description string) *Route {
+	r.node.description = description
+	return r
+}
+
+// Node returns the underlying trie's node.
+func (r *Route) Node() *Node {
+	return r.node
+}
+
+// Pattern returns the registered pattern, including the group's prefix, i.e "/api/users/:id".
+func (r *Route) Pattern() string {
+	return r.node.key
+}
+
+// GetName returns the name of the route, see `Name`.
+func (r *Route) GetName() string {
+	return r.node.Tag
+}
+
+// GetMethods returns the methods that the route is limited to, see `Methods`.
+func (r *Route) GetMethods() []string {
+	return r.methods
+}
+
+// Handler returns the registered handler of the route, without its wrappers.
+func (r *Route) Handler() http.Handler {
+	return r.handler
+}
+
+// apply installs the route's handler, wrapped, to its node.
+func (r *Route) apply() {
+	h := r.handler
+	for i := len(r.wrappers) - 1; i >= 0; i-- {
+		h = r.wrappers[i](h)
+	}
+
+	if _, ok := r.handler.(ParamsHandler); ok && len(r.wrappers) > 0 {
+		// the wrappers can replace the response writer.
+		h = ParamsToContext(h)
+	}
+
+	if len(r.methods) == 0 {
+		r.node.Handler = h
+		return
+	}
+
+	mh, ok := r.node.Handler.(*MethodHandler)
+	if !ok {
+		if mh = r.methodHandler; mh == nil {
+			mh = Methods()
+		}
+		r.node.Handler = mh
+	}
+
+	for _, method := range r.methods {
+		mh.Handle(method, h)
+	}
+}
+
+// Route returns a route by its name, see `Route#Name`, or nil if not found.
+// Routes of all the groups can be retrieved by any of them.
+func (m *Mux) Route(name string) *Route {
+	return m.top().named[name]
+}
+
+func (m *Mux) top() *Mux {
+	g := m
+	for g.parent != nil {
+		g = g.parent
+	}
+
+	return g
+}
+
+func (m *Mux) registerName(name string, r *Route) {
+	if m.named == nil {
+		m.named = make(map[string]*Route)
+	}
+
+	m.named[name] = r
+}
+
+func (m *Mux) unregisterName(name string, r *Route) {
+	if m.named[name] == r {
+		delete(m.named, name)
+	}
+}
+
+// Meta returns a metadata value of the node, see `Route#Meta`.
+func (n *Node) Meta(key string) interface{} {
+	return n.meta[key]
+}
+
+// Description returns the description of the node, see `Route#Describe`.
+func (n *Node) Description() string {
+	return n.description
+}
@@ -0,0 +1,115 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	header := func(key, value string) Wrapper {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add(key, value)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	mux := NewMux()
+	api := mux.Of("/api")
+
+	api.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "list users")
+	}).Name("users.list").Methods(http.MethodGet)
+
+	api.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "create user")
+	}).Name("users.create").Methods(http.MethodPost).Use(header("X-Mw", "1"), header("X-Mw", "2"))
+
+	api.HandleFunc("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "user %s", GetParam(w, "id"))
+	}).Name("users.get").Meta("auth", "admin").Describe("Returns a user by its id")
+
+	route := mux.Route("users.get")
+	if route == nil {
+		t.Fatalf("expected route to be found by its name")
+	}
+
+	if expected, got := "/api/users/:id", route.Pattern(); expected != got {
+		t.Fatalf("expected pattern: '%s' but got '%s'", expected, got)
+	}
+
+	if expected, got := "users.get", route.Node().Tag; expected != got {
+		t.Fatalf("expected node's tag: '%s' but got '%s'", expected, got)
+	}
+
+	if expected, got := "admin", route.Node().Meta("auth"); expected != got {
+		t.Fatalf("expected node's meta: '%v' but got '%v'", expected, got)
+	}
+
+	if expected, got := "Returns a user by its id", route.Node().Description(); expected != got {
+		t.Fatalf("expected node's description: '%s' but got '%s'", expected, got)
+	}
+
+	if api.Route("users.create") == nil {
+		t.Fatalf("expected route to be found by its name through the group")
+	}
+
+	tests := []struct {
+		method     string
+		path       string
+		statusCode int
+		expected   string
+		mw         []string
+	}{
+		{http.MethodGet, "/api/users", http.StatusOK, "list users", nil},
+		{http.MethodPost, "/api/users", http.StatusOK, "create user", []string{"1", "2"}},
+		{http.MethodDelete, "/api/users", http.StatusMethodNotAllowed, "Method Not Allowed\n", nil},
+		{http.MethodDelete, "/api/users/42", http.StatusOK, "user 42", nil},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s %s: expected status code: %d but got %d", i, tt.method, tt.path, expected, got)
+		}
+
+		if expected, got := tt.expected, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s %s: expected to receive '%s' but got '%s'", i, tt.method, tt.path, expected, got)
+		}
+
+		if expected, got := tt.mw, rec.Header()["X-Mw"]; fmt.Sprint(expected) != fmt.Sprint(got) {
+			t.Fatalf("[%d] %s %s: expected middleware headers: %v but got %v", i, tt.method, tt.path, expected, got)
+		}
+	}
+}
+
+func TestGetRoute(t *testing.T) {
+	mux := NewMux()
+	mux.RouteContext = true
+
+	v1 := mux.Of("/v1")
+	v1.HandleFunc("/users/:id/posts/*rest", func(w http.ResponseWriter, r *http.Request) {
+		n := GetRoute(w)
+		if n != GetRequestRoute(r) {
+			t.Fatalf("expected the same route through the response writer and the request")
+		}
+
+		fmt.Fprintf(w, "%s|%s|%v|%v|%s|%s", n.Pattern(), n.Tag, n.Data, n.ParamKeys(), n.Prefix(), n.Group().Prefix())
+	}).Name("user.posts").Node().Data = "posts_data"
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users/42/posts/a/b", nil))
+
+	if expected, got := "/v1/users/:id/posts/*rest|user.posts|posts_data|[id rest]|/v1|/v1", rec.Body.String(); expected != got {
+		t.Fatalf("expected to receive '%s' but got '%s'", expected, got)
+	}
+
+	if GetRoute(rec) != nil {
+		t.Fatalf("expected nil route for a foreign response writer")
+	}
+}
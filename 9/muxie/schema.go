@@ -0,0 +1,687 @@
+package muxie
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema which validates the request bodies of a route,
+// it's attached through the `WithData` or the `Route#Schema`, i.e
+//
+//	mux.Handle("/users", createUser).Schema(muxie.MustCompileSchema(`{
+//		"type": "object",
+//		"required": ["name"],
+//		"properties": {"name": {"type": "string", "minLength": 1}}
+//	}`))
+//
+// The body, up to the `Route#MaxBodySize`, is validated before the route's handler, an invalid one is passed
+// as `ValidationErrors` to the `RespondError`, which responds with a 400 listing the violations.
+//
+// The supported keywords are the:
+// type, enum, const, minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf,
+// minLength, maxLength, pattern, format (email, uuid, date, date-time),
+// items, minItems, maxItems, uniqueItems, properties, required, additionalProperties,
+// minProperties, maxProperties, allOf, anyOf, oneOf, not and local $ref (i.e "#/definitions/user").
+// Remote references are not fetched, they fail to compile.
+type Schema struct {
+	// for the boolean schemas, nil for the object ones.
+	allow *bool
+
+	types      []string
+	enum       []interface{}
+	constValue interface{}
+	hasConst   bool
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *float64
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+	format               string
+
+	items              *Schema
+	minItems, maxItems *int
+	uniqueItems        bool
+
+	properties                   map[string]*Schema
+	propertyNames                []string // sorted, for deterministic violations.
+	required                     []string
+	additionalProperties         *Schema
+	minProperties, maxProperties *int
+
+	allOf, anyOf, oneOf []*Schema
+	not                 *Schema
+	ref                 *Schema
+}
+
+// CompileSchema compiles a JSON Schema document.
+func CompileSchema(document []byte) (*Schema, error) {
+	var root interface{}
+	if err := json.Unmarshal(document, &root); err != nil {
+		return nil, fmt.Errorf("muxie: schema: %v", err)
+	}
+
+	c := &schemaCompiler{root: root, refs: make(map[string]*Schema)}
+	return c.compile(root, "#")
+}
+
+// MustCompileSchema same as `CompileSchema` but it panics on errors,
+// it's useful to compile the schemas on the routes' registration.
+func MustCompileSchema(document string) *Schema {
+	s, err := CompileSchema([]byte(document))
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Schema attaches a JSON Schema to the route, its request bodies are validated before its handler.
+// It's stored per method, so each route of the same pattern can have its own schema, i.e
+//
+//	mux.HandleFunc("/users", createUser).Methods(http.MethodPost).Schema(createSchema)
+//	mux.HandleFunc("/users", replaceUser).Methods(http.MethodPut).Schema(replaceSchema)
+func (r *Route) Schema(s *Schema) *Route {
+	r.schema = s
+	r.applySchema()
+	return r
+}
+
+// applySchema stores the route's schema to its node for the route's methods, or for any method if not limited.
+func (r *Route) applySchema() {
+	if r.schema == nil {
+		return
+	}
+
+	if r.node.schemas == nil {
+		r.node.schemas = make(map[string]*Schema)
+	}
+
+	if len(r.methods) == 0 {
+		r.node.schemas[""] = r.schema
+		return
+	}
+
+	if r.node.schemas[""] == r.schema {
+		// set before the `Methods`.
+		delete(r.node.schemas, "")
+	}
+
+	for _, method := range r.methods {
+		r.node.schemas[method] = r.schema
+	}
+}
+
+// schemaFor returns the schema of the "method" for the node "n", if any,
+// a `Schema` stored as the node's `Data`, i.e through `WithData`, is used for any method.
+func schemaFor(n *Node, method string) *Schema {
+	if s, ok := n.schemas[method]; ok {
+		return s
+	}
+
+	if s, ok := n.schemas[""]; ok {
+		return s
+	}
+
+	s, _ := n.Data.(*Schema)
+	return s
+}
+
+type schemaCompiler struct {
+	root interface{}
+	refs map[string]*Schema
+}
+
+func (c *schemaCompiler) compile(raw interface{}, path string) (*Schema, error) {
+	if b, ok := raw.(bool); ok {
+		return &Schema{allow: &b}, nil
+	}
+
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("muxie: schema: %s: expected an object or a boolean", path)
+	}
+
+	s := new(Schema)
+	var err error
+
+	if ref, ok := obj["$ref"].(string); ok {
+		if s.ref, err = c.resolve(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	switch typ := obj["type"].(type) {
+	case string:
+		s.types = []string{typ}
+	case []interface{}:
+		for _, t := range typ {
+			if name, ok := t.(string); ok {
+				s.types = append(s.types, name)
+			}
+		}
+	}
+
+	if enum, ok := obj["enum"].([]interface{}); ok {
+		s.enum = enum
+	}
+	s.constValue, s.hasConst = obj["const"]
+
+	numbers := map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf":       &s.multipleOf,
+	}
+	for key, dest := range numbers {
+		if v, ok := obj[key].(float64); ok {
+			*dest = &v
+		}
+	}
+
+	ints := map[string]**int{
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+	}
+	for key, dest := range ints {
+		if v, ok := obj[key].(float64); ok {
+			n := int(v)
+			*dest = &n
+		}
+	}
+
+	if pattern, ok := obj["pattern"].(string); ok {
+		if s.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("muxie: schema: %s/pattern: %v", path, err)
+		}
+	}
+	s.format, _ = obj["format"].(string)
+	s.uniqueItems, _ = obj["uniqueItems"].(bool)
+
+	if items, ok := obj["items"]; ok {
+		if s.items, err = c.compile(items, path+"/items"); err != nil {
+			return nil, err
+		}
+	}
+
+	if properties, ok := obj["properties"].(map[string]interface{}); ok {
+		s.properties = make(map[string]*Schema, len(properties))
+		for name, property := range properties {
+			if s.properties[name], err = c.compile(property, path+"/properties/"+escapeJSONPointer(name)); err != nil {
+				return nil, err
+			}
+			s.propertyNames = append(s.propertyNames, name)
+		}
+		sort.Strings(s.propertyNames)
+	}
+
+	if required, ok := obj["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				s.required = append(s.required, name)
+			}
+		}
+	}
+
+	if additional, ok := obj["additionalProperties"]; ok {
+		if s.additionalProperties, err = c.compile(additional, path+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+
+	lists := map[string]*[]*Schema{
+		"allOf": &s.allOf,
+		"anyOf": &s.anyOf,
+		"oneOf": &s.oneOf,
+	}
+	for key, dest := range lists {
+		list, ok := obj[key].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for i, raw := range list {
+			sub, err := c.compile(raw, path+"/"+key+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			*dest = append(*dest, sub)
+		}
+	}
+
+	if not, ok := obj["not"]; ok {
+		if s.not, err = c.compile(not, path+"/not"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// resolve compiles the schema of a local reference, i.e "#/definitions/user",
+// once, so recursive schemas are supported.
+func (c *schemaCompiler) resolve(ref string) (*Schema, error) {
+	if s, ok := c.refs[ref]; ok {
+		return s, nil
+	}
+
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("muxie: schema: reference '%s' is not local", ref)
+	}
+
+	target := c.root
+	if pointer := ref[1:]; pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+			obj, ok := target.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("muxie: schema: reference '%s' not found", ref)
+			}
+
+			if target, ok = obj[token]; !ok {
+				return nil, fmt.Errorf("muxie: schema: reference '%s' not found", ref)
+			}
+		}
+	}
+
+	// register it before compiling, for the recursive references.
+	s := new(Schema)
+	c.refs[ref] = s
+
+	compiled, err := c.compile(target, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	*s = *compiled
+	return s, nil
+}
+
+// Validate validates a decoded JSON value, i.e the result of a `json.Unmarshal` to an interface{},
+// it returns nil if the value is valid.
+func (s *Schema) Validate(v interface{}) ValidationErrors {
+	var errs ValidationErrors
+	s.validate(v, "", &errs)
+	return errs
+}
+
+// ValidateJSON decodes and validates the JSON "data".
+func (s *Schema) ValidateJSON(data []byte) ValidationErrors {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return ValidationErrors{{Message: "invalid JSON: " + err.Error()}}
+	}
+
+	return s.Validate(v)
+}
+
+func (s *Schema) validate(v interface{}, path string, errs *ValidationErrors) {
+	report := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.allow != nil {
+		if !*s.allow {
+			report("is not allowed")
+		}
+		return
+	}
+
+	if s.ref != nil {
+		s.ref.validate(v, path, errs)
+	}
+
+	if len(s.types) > 0 && !matchesSchemaType(v, s.types) {
+		report("must be of type %s", strings.Join(s.types, " or "))
+		return
+	}
+
+	if s.enum != nil {
+		found := false
+		for _, e := range s.enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report("must be one of %s", jsonString(s.enum))
+		}
+	}
+
+	if s.hasConst && !reflect.DeepEqual(s.constValue, v) {
+		report("must be equal to %s", jsonString(s.constValue))
+	}
+
+	switch value := v.(type) {
+	case float64:
+		s.validateNumber(value, report)
+	case string:
+		s.validateString(value, report)
+	case []interface{}:
+		s.validateArray(value, path, errs, report)
+	case map[string]interface{}:
+		s.validateObject(value, path, errs, report)
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(v, path, errs)
+	}
+
+	if len(s.anyOf) > 0 {
+		valid := false
+		for _, sub := range s.anyOf {
+			if len(sub.Validate(v)) == 0 {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			report("must match at least one of the schemas")
+		}
+	}
+
+	if len(s.oneOf) > 0 {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if len(sub.Validate(v)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			report("must match exactly one of the schemas, matched %d", matched)
+		}
+	}
+
+	if s.not != nil && len(s.not.Validate(v)) == 0 {
+		report("must not match the schema")
+	}
+}
+
+func (s *Schema) validateNumber(n float64, report func(string, ...interface{})) {
+	if s.minimum != nil && n < *s.minimum {
+		report("must be >= %v", *s.minimum)
+	}
+	if s.maximum != nil && n > *s.maximum {
+		report("must be <= %v", *s.maximum)
+	}
+	if s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum {
+		report("must be > %v", *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum {
+		report("must be < %v", *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil && *s.multipleOf != 0 {
+		if !isMultipleOf(n, *s.multipleOf) {
+			report("must be a multiple of %v", *s.multipleOf)
+		}
+	}
+}
+
+// isMultipleOf reports whether the "n" is a multiple of the "m", they are compared as their shortest decimals,
+// so 0.3 is a multiple of 0.1 even if their floats are not.
+func isMultipleOf(n, m float64) bool {
+	x, ok := new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+	if !ok {
+		return false
+	}
+
+	y, ok := new(big.Rat).SetString(strconv.FormatFloat(m, 'g', -1, 64))
+	if !ok || y.Sign() == 0 {
+		return false
+	}
+
+	return x.Quo(x, y).IsInt()
+}
+
+var (
+	emailFormat    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uuidFormat     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	dateFormat     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	dateTimeFormat = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})$`)
+)
+
+func (s *Schema) validateString(str string, report func(string, ...interface{})) {
+	length := utf8.RuneCountInString(str)
+	if s.minLength != nil && length < *s.minLength {
+		report("length must be >= %d", *s.minLength)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		report("length must be <= %d", *s.maxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		report("must match the pattern %s", s.pattern.String())
+	}
+
+	var format *regexp.Regexp
+	switch s.format {
+	case "email":
+		format = emailFormat
+	case "uuid":
+		format = uuidFormat
+	case "date":
+		format = dateFormat
+	case "date-time":
+		format = dateTimeFormat
+	}
+	// unknown formats are ignored, as the specification allows.
+	if format != nil && !format.MatchString(str) {
+		report("must be a valid %s", s.format)
+	}
+}
+
+func (s *Schema) validateArray(items []interface{}, path string, errs *ValidationErrors, report func(string, ...interface{})) {
+	if s.minItems != nil && len(items) < *s.minItems {
+		report("must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(items) > *s.maxItems {
+		report("must have at most %d items", *s.maxItems)
+	}
+
+	if s.uniqueItems {
+	unique:
+		for i := range items {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(items[i], items[j]) {
+					report("must have unique items")
+					break unique
+				}
+			}
+		}
+	}
+
+	if s.items != nil {
+		for i, item := range items {
+			s.items.validate(item, path+"/"+strconv.Itoa(i), errs)
+		}
+	}
+}
+
+func (s *Schema) validateObject(obj map[string]interface{}, path string, errs *ValidationErrors, report func(string, ...interface{})) {
+	if s.minProperties != nil && len(obj) < *s.minProperties {
+		report("must have at least %d properties", *s.minProperties)
+	}
+	if s.maxProperties != nil && len(obj) > *s.maxProperties {
+		report("must have at most %d properties", *s.maxProperties)
+	}
+
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, ValidationError{Field: path + "/" + escapeJSONPointer(name), Message: "is required"})
+		}
+	}
+
+	for _, name := range s.propertyNames {
+		if value, ok := obj[name]; ok {
+			s.properties[name].validate(value, path+"/"+escapeJSONPointer(name), errs)
+		}
+	}
+
+	if s.additionalProperties != nil {
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			if _, ok := s.properties[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fieldPath := path + "/" + escapeJSONPointer(name)
+			if allow := s.additionalProperties.allow; allow != nil && !*allow {
+				*errs = append(*errs, ValidationError{Field: fieldPath, Message: "is not allowed"})
+				continue
+			}
+
+			s.additionalProperties.validate(obj[name], fieldPath, errs)
+		}
+	}
+}
+
+func matchesSchemaType(v interface{}, types []string) bool {
+	for _, typ := range types {
+		switch value := v.(type) {
+		case nil:
+			if typ == "null" {
+				return true
+			}
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case float64:
+			if typ == "number" || (typ == "integer" && value == math.Trunc(value)) {
+				return true
+			}
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case []interface{}:
+			if typ == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if typ == "object" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func escapeJSONPointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+func jsonString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// MaxBodySize sets the limit of the request bodies that are read to be validated against the route's `Schema`,
+// a negative value means no limit. Defaults to the limit of the route's `JSON` handler, if any,
+// otherwise to the `DefaultMaxBodySize`.
+func (r *Route) MaxBodySize(limit int64) *Route {
+	r.node.maxBodySize = limit
+	return r
+}
+
+// bodyLimiter is implemented by the `JSONHandler`.
+type bodyLimiter interface {
+	bodyLimit() int64
+}
+
+// bodyLimit returns the limit of the request body of the route, see `Route#MaxBodySize`.
+func bodyLimit(n *Node, h http.Handler, r *http.Request) int64 {
+	if n.maxBodySize != 0 {
+		return n.maxBodySize
+	}
+
+	if mh, ok := h.(*MethodHandler); ok {
+		h = mh.handlers[r.Method]
+	}
+
+	if l, ok := h.(bodyLimiter); ok {
+		return l.bodyLimit()
+	}
+
+	return DefaultMaxBodySize
+}
+
+// validateBody validates the request body against the route's `Schema`, if any,
+// it reports false if the body is invalid, the violations are already responded.
+// The returned request holds a copy of the body for the handler.
+func validateBody(n *Node, h http.Handler, pw *paramsWriter, r *http.Request) (*http.Request, bool) {
+	s := schemaFor(n, r.Method)
+	if s == nil {
+		return r, true
+	}
+
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		if r.ContentLength == 0 {
+			return r, true
+		}
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		reader := r.Body
+		if limit := bodyLimit(n, h, r); limit >= 0 {
+			reader = http.MaxBytesReader(pw, r.Body, limit)
+		}
+
+		body, err = io.ReadAll(reader)
+		r.Body.Close()
+		if err != nil {
+			statusCode := http.StatusBadRequest
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				statusCode = http.StatusRequestEntityTooLarge
+			}
+
+			RespondError(pw, r, &StatusError{Code: statusCode, Err: err})
+			return r, false
+		}
+	}
+
+	var errs ValidationErrors
+	if len(bytes.TrimSpace(body)) == 0 {
+		errs = ValidationErrors{{Message: "request body is required"}}
+	} else {
+		errs = s.ValidateJSON(body)
+	}
+
+	if len(errs) > 0 {
+		RespondError(pw, r, errs)
+		return r, false
+	}
+
+	rCopy := new(http.Request)
+	*rCopy = *r
+	rCopy.Body = io.NopCloser(bytes.NewReader(body))
+	rCopy.ContentLength = int64(len(body))
+	return rCopy, true
+}
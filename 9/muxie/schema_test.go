@@ -0,0 +1,184 @@
+package muxie
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["name", "email"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "maxLength": 10},
+		"email": {"type": "string", "format": "email"},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "uniqueItems": true, "maxItems": 3},
+		"manager": {"$ref": "#/definitions/person"}
+	},
+	"definitions": {
+		"person": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {"name": {"type": "string"}, "manager": {"$ref": "#/definitions/person"}}
+		}
+	}
+}`
+
+func TestSchemaValidate(t *testing.T) {
+	s := MustCompileSchema(userSchema)
+
+	tests := []struct {
+		body       string
+		violations ValidationErrors
+	}{
+		{`{"name":"kataras","email":"k@example.com","age":30,"role":"admin","tags":["go"],"manager":{"name":"a","manager":{"name":"b"}}}`, nil},
+		{`{"name":"k","email":"invalid","age":30.5,"extra":true}`, ValidationErrors{
+			{Field: "/age", Message: "must be of type integer"},
+			{Field: "/email", Message: "must be a valid email"},
+			{Field: "/name", Message: "length must be >= 2"},
+			{Field: "/extra", Message: "is not allowed"},
+		}},
+		{`{"name":"kataras","email":"k@example.com","role":"guest","tags":["Go","go","go","x"]}`, ValidationErrors{
+			{Field: "/role", Message: `must be one of ["admin","user"]`},
+			{Field: "/tags", Message: "must have at most 3 items"},
+			{Field: "/tags", Message: "must have unique items"},
+			{Field: "/tags/0", Message: "must match the pattern ^[a-z]+$"},
+		}},
+		{`{"name":"kataras","email":"k@example.com","manager":{"manager":{}}}`, ValidationErrors{
+			{Field: "/manager/name", Message: "is required"},
+			{Field: "/manager/manager/name", Message: "is required"},
+		}},
+		{`[]`, ValidationErrors{{Field: "", Message: "must be of type object"}}},
+	}
+
+	for i, tt := range tests {
+		if expected, got := tt.violations, s.ValidateJSON([]byte(tt.body)); !reflect.DeepEqual(expected, got) {
+			t.Fatalf("[%d] expected violations:\n%v\nbut got:\n%v", i, expected, got)
+		}
+	}
+
+	for _, invalid := range []string{
+		`{"$ref": "https://example.com/schema.json"}`,
+		`{"$ref": "#/definitions/missing"}`,
+		`{"type": "string", "pattern": "("}`,
+		`"string"`,
+	} {
+		if _, err := CompileSchema([]byte(invalid)); err == nil {
+			t.Fatalf("expected a compile error for: %s", invalid)
+		}
+	}
+}
+
+func TestSchemaMultipleOf(t *testing.T) {
+	s := MustCompileSchema(`{"type": "number", "multipleOf": 0.1}`)
+
+	for _, valid := range []string{`0.3`, `0.7`, `1.1`, `3`, `-0.2`} {
+		if violations := s.ValidateJSON([]byte(valid)); len(violations) > 0 {
+			t.Fatalf("%s: expected no violations but got: %v", valid, violations)
+		}
+	}
+
+	for _, invalid := range []string{`0.35`, `0.01`} {
+		if violations := s.ValidateJSON([]byte(invalid)); len(violations) != 1 {
+			t.Fatalf("%s: expected a multipleOf violation but got: %v", invalid, violations)
+		}
+	}
+}
+
+func TestMuxSchema(t *testing.T) {
+	mux := NewMux()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, "created %s", body)
+	}
+
+	mux.Handle("/users", Methods().
+		HandleFunc(http.MethodGet, func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "list") }).
+		HandleFunc(http.MethodPost, handler)).Schema(MustCompileSchema(userSchema))
+	mux.Routes.Insert("/tags", WithHandler(http.HandlerFunc(handler)),
+		WithData(MustCompileSchema(`{"type": "array", "items": {"type": "string"}}`)))
+	// each method of the same pattern has its own schema.
+	mux.HandleFunc("/items", handler).Methods(http.MethodPost).
+		Schema(MustCompileSchema(`{"type": "object", "required": ["name"]}`))
+	mux.HandleFunc("/items", handler).Schema(MustCompileSchema(`{"type": "object", "required": ["id"]}`)).
+		Methods(http.MethodPut)
+
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		statusCode int
+		response   string
+	}{
+		{http.MethodGet, "/users", "", http.StatusOK, "list"},
+		{http.MethodPost, "/users", `{"name":"kataras","email":"k@example.com"}`, http.StatusOK, `created {"name":"kataras","email":"k@example.com"}`},
+		{http.MethodPost, "/users", `{"name":"kataras"}`, http.StatusBadRequest,
+			`{"message":"Bad Request","violations":[{"field":"/email","message":"is required"}]}` + "\n"},
+		{http.MethodPost, "/users", "", http.StatusBadRequest,
+			`{"message":"Bad Request","violations":[{"field":"","message":"request body is required"}]}` + "\n"},
+		{http.MethodPost, "/tags", `["a", 1]`, http.StatusBadRequest,
+			`{"message":"Bad Request","violations":[{"field":"/1","message":"must be of type string"}]}` + "\n"},
+		{http.MethodPost, "/tags", `["a"]`, http.StatusOK, `created ["a"]`},
+		{http.MethodPost, "/items", `{"name":"a"}`, http.StatusOK, `created {"name":"a"}`},
+		{http.MethodPost, "/items", `{"id":1}`, http.StatusBadRequest,
+			`{"message":"Bad Request","violations":[{"field":"/name","message":"is required"}]}` + "\n"},
+		{http.MethodPut, "/items", `{"id":1}`, http.StatusOK, `created {"id":1}`},
+		{http.MethodPut, "/items", `{"name":"a"}`, http.StatusBadRequest,
+			`{"message":"Bad Request","violations":[{"field":"/id","message":"is required"}]}` + "\n"},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s %s: expected status code: %d but got %d", i, tt.method, tt.path, expected, got)
+		}
+
+		if expected, got := tt.response, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s %s: expected to receive '%s' but got '%s'", i, tt.method, tt.path, expected, got)
+		}
+	}
+}
+
+func TestMuxSchemaBodySize(t *testing.T) {
+	schema := MustCompileSchema(`{"type": "object", "properties": {"data": {"type": "string"}}}`)
+
+	mux := NewMux()
+	mux.Handle("/large", JSON(func(ctx context.Context, req struct {
+		Data string `json:"data"`
+	}) (int, error) {
+		return len(req.Data), nil
+	}).MaxBodySize(10<<20)).Schema(schema)
+	mux.HandleFunc("/small", func(w http.ResponseWriter, r *http.Request) {}).Schema(schema).MaxBodySize(16)
+
+	large := `{"data":"` + strings.Repeat("a", 2<<20) + `"}`
+
+	tests := []struct {
+		path       string
+		body       string
+		statusCode int
+	}{
+		// the limit of the JSON handler.
+		{"/large", large, http.StatusOK},
+		{"/small", `{"data":"a"}`, http.StatusOK},
+		{"/small", `{"data":"more than 16 bytes"}`, http.StatusRequestEntityTooLarge},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s: expected status code: %d but got %d", i, tt.path, expected, got)
+		}
+	}
+}
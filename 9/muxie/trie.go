@@ -1,7 +1,9 @@
 package muxie
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 )
 
@@ -16,6 +18,12 @@ type Trie struct {
 	// if true then it will handle any path if not other parent wildcard exists,
 	// so even 404 (on http services) is up to it, see Trie#Insert.
 	hasRootWildcard bool
+	// if true then at least one wildcard is followed by other segments, i.e /files/*path/edit,
+	// and the search falls back to a backtracking one before the parent wildcards, see `matchExact`.
+	hasMidWildcard bool
+	// if true then at least one named parameter is constrained by a regular expression, i.e :year([0-9]{4}),
+	// the search falls back to a backtracking one too.
+	hasRegexpParam bool
 }
 
 func NewTrie() *Trie {
@@ -35,6 +43,25 @@ func WithHandler(handler http.Handler) InsertOption {
 	}
 }
 
+// WithMatch registers a handler which is served only when all of the "matchers" are passing.
+// A node can hold many of them, they are evaluated in the order they were registered
+// and if none of them matches then the node's `Handler` is used, if any.
+func WithMatch(handler http.Handler, matchers ...Matcher) InsertOption {
+	return func(n *Node) {
+		n.candidates = append(n.candidates, &candidate{
+			matchers: matchers,
+			handler:  handler,
+		})
+	}
+}
+
+// withMux sets the group which the node belongs to.
+func withMux(m *Mux) InsertOption {
+	return func(n *Node) {
+		n.mux = m
+	}
+}
+
 func WithTag(tag string) InsertOption {
 	return func(n *Node) {
 		if n.Tag == "" {
@@ -57,6 +84,17 @@ func (t *Trie) Insert(key string, options ...InsertOption) {
 	}
 }
 
+// insertNode same as `Insert` but it does not reset the values of an already registered node,
+// same pattern can be inserted many times, i.e with different matchers or versions.
+func (t *Trie) insertNode(key string, options ...InsertOption) *Node {
+	n := t.insertPath(key)
+	for _, opt := range options {
+		opt(n)
+	}
+
+	return n
+}
+
 func (t *Trie) InsertRoute(pattern, routeName string, handler http.Handler) {
 	t.insert(pattern, routeName, nil, handler)
 }
@@ -91,13 +129,36 @@ func resolveStaticPart(key string) string {
 	return key[:i]
 }
 
+// parseRegexpParam parses a regexp constrained named parameter's segment,
+// i.e ":year([0-9]{4})" to "year" and "[0-9]{4}".
+// Note that the expression can't contain a path separator.
+func parseRegexpParam(s string) (name, expr string, ok bool) {
+	idx := strings.IndexByte(s, '(')
+	if idx <= 1 || s[len(s)-1] != ')' {
+		return
+	}
+
+	return s[1:idx], s[idx+1 : len(s)-1], true
+}
+
 func (t *Trie) insert(key, tag string, optionalData interface{}, handler http.Handler) *Node {
+	n := t.insertPath(key)
+
+	n.Tag = tag
+	n.Handler = handler
+	n.Data = optionalData
+
+	return n
+}
+
+// insertPath creates the nodes of the "key", if they don't exist, and returns its end node.
+func (t *Trie) insertPath(key string) *Node {
 	input := slowPathSplit(key)
 
 	n := t.root
 	var paramKeys []string
 
-	for _, s := range input {
+	for segIdx, s := range input {
 		c := s[0]
 
 		if isParam, isWildcard := c == ParamStart[0], c == WildcardParamStart[0]; isParam || isWildcard {
@@ -106,14 +167,33 @@ func (t *Trie) insert(key, tag string, optionalData interface{}, handler http.Ha
 
 			// if node has already a wildcard, don't force a value, check for true only.
 			if isParam {
-				n.childNamedParameter = true
-				s = ParamStart
+				if name, expr, ok := parseRegexpParam(s); ok {
+					paramKeys[len(paramKeys)-1] = name
+					t.hasRegexpParam = true
+					s = ParamStart + "(" + expr + ")"
+					if !n.hasChild(s) {
+						child := NewNode()
+						// compile once, at insert time.
+						re, err := regexp.Compile("^(?:" + expr + ")$")
+						if err != nil {
+							panic(fmt.Sprintf("muxie: invalid regexp of parameter '%s' in '%s': %v", name, key, err))
+						}
+						child.re = re
+						n.addChild(s, child)
+						n.regexpChildren = append(n.regexpChildren, child)
+					}
+				} else {
+					n.childNamedParameter = true
+					s = ParamStart
+				}
 			}
 
 			if isWildcard {
 				n.childWildcardParameter = true
 				s = WildcardParamStart
-				if t.root == n {
+				if segIdx < len(input)-1 {
+					t.hasMidWildcard = true
+				} else if t.root == n {
 					t.hasRootWildcard = true
 				}
 			}
@@ -127,10 +207,6 @@ func (t *Trie) insert(key, tag string, optionalData interface{}, handler http.Ha
 		n = n.getChild(s)
 	}
 
-	n.Tag = tag
-	n.Handler = handler
-	n.Data = optionalData
-
 	n.paramKeys = paramKeys
 	n.key = key
 	n.staticKey = resolveStaticPart(key)
@@ -179,22 +255,49 @@ func (fn Setter) Set(key, value string) {
 }
 
 func (t *Trie) Search(q string, params ParamsSetter) *Node {
+	return t.search(q, params, nil)
+}
+
+// search is the implementation of `Search` and `Explain`, "ex" is nil on `Search`.
+func (t *Trie) search(q string, params ParamsSetter, ex *Explanation) *Node {
 	end := len(q)
 	n := t.root
 	if end == 1 && q[0] == pathSepB {
+		if ex != nil {
+			ex.step(pathSep, n, BranchStatic, "root path")
+		}
 		return n.getChild(pathSep)
 	}
 
 	start := 1
 	i := 1
-	var paramValues []string
+	// most of the routes have a few parameters, keep their values on the stack.
+	var paramValuesBuf [8]string
+	paramValues := paramValuesBuf[:0]
 
 	for {
 		if i == end || q[i] == pathSepB {
 			if child := n.getChild(q[start:i]); child != nil {
+				if ex != nil {
+					ex.step(q[start:i], n, BranchStatic, "")
+				}
 				n = child
+			} else if child := n.findRegexpChild(q[start:i]); child != nil {
+				if ex != nil {
+					ex.step(q[start:i], n, BranchRegexp, "no static child")
+				}
+				n = child
+				if ln := len(paramValues); cap(paramValues) > ln {
+					paramValues = paramValues[:ln+1]
+					paramValues[ln] = q[start:i]
+				} else {
+					paramValues = append(paramValues, q[start:i])
+				}
 			} else if n.childNamedParameter { // && n.childWildcardParameter == false {
 				//	println("dynamic NAMED element for: " + q[start:i] + " found ")
+				if ex != nil {
+					ex.step(q[start:i], n, BranchParam, "no static child")
+				}
 				n = n.getChild(ParamStart)
 				if ln := len(paramValues); cap(paramValues) > ln {
 					paramValues = paramValues[:ln+1]
@@ -204,35 +307,42 @@ func (t *Trie) Search(q string, params ParamsSetter) *Node {
 				}
 			} else if n.childWildcardParameter {
 				//	println("dynamic WILDCARD element for: " + q[start:i] + " found ")
-				n = n.getChild(WildcardParamStart)
-				if ln := len(paramValues); cap(paramValues) > ln {
-					paramValues = paramValues[:ln+1]
-					paramValues[ln] = q[start:]
-				} else {
-					paramValues = append(paramValues, q[start:])
+				wild := n.getChild(WildcardParamStart)
+				if len(wild.children) > 0 {
+					// mid-pattern wildcard, i.e /files/*path/edit, it consumes one or more segments
+					// and the rest of the path should match one of its children, the shortest match wins.
+					for k := i; k < end; k++ {
+						if q[k] != pathSepB {
+							continue
+						}
+
+						if found, values := matchExact(wild, q[k:], append(paramValues, q[start:k])); found != nil {
+							if ex != nil {
+								ex.step(q[start:k], n, BranchMidWildcard, "no static or named parameter child")
+							}
+							setParams(found, values, params)
+							return found
+						}
+					}
 				}
-				break
-			} else {
-				n = n.findClosestParentWildcardNode()
-				if n != nil {
-					// means that it has :param/static and *wildcard, we go trhough the :param
-					// but the next path segment is not the /static, so go back to *wildcard
-					// instead of not found.
-					//
-					// Fixes:
-					// /hello/*p
-					// /hello/:p1/static/:p2
-					// req: http://localhost:8080/hello/dsadsa/static/dsadsa => found
-					// req: http://localhost:8080/hello/dsadsa => but not found!
-					// and
-					// /second/wild/*p
-					// /second/wild/static/otherstatic/
-					// req: /second/wild/static/otherstatic/random => but not found!
-					params.Set(n.paramKeys[0], q[len(n.staticKey):])
-					return n
+
+				if wild.end {
+					if ex != nil {
+						ex.step(q[start:], n, BranchWildcard, "no static or named parameter child")
+					}
+					n = wild
+					if ln := len(paramValues); cap(paramValues) > ln {
+						paramValues = paramValues[:ln+1]
+						paramValues[ln] = q[start:]
+					} else {
+						paramValues = append(paramValues, q[start:])
+					}
+					break
 				}
 
-				return nil
+				return t.searchFallback(n, q, params, ex, q[start:i], "no suffix of the mid-pattern wildcard matches")
+			} else {
+				return t.searchFallback(n, q, params, ex, q[start:i], "no child matches the segment")
 			}
 
 			if i == end {
@@ -248,9 +358,18 @@ func (t *Trie) Search(q string, params ParamsSetter) *Node {
 	}
 
 	if n == nil || !n.end {
+		if t.backtracks() {
+			if found := t.searchBacktrack(q, params, ex); found != nil {
+				return found
+			}
+		}
+
 		if n != nil { // we need it on both places, on last segment (below) or on the first unnknown (above).
 			if n = n.findClosestParentWildcardNode(); n != nil {
-				params.Set(n.paramKeys[0], q[len(n.staticKey):])
+				if ex != nil {
+					ex.step(q, n, BranchClosestWildcard, "matched node is not a registered route")
+				}
+				setWildcardParams(n, q, params)
 				return n
 			}
 		}
@@ -263,19 +382,180 @@ func (t *Trie) Search(q string, params ParamsSetter) *Node {
 			// Reqs: /other2/staticed will be handled
 			// the /other2/*myparam and not the root wildcard, which is what we want.
 			//
+			if ex != nil {
+				ex.step(q, t.root, BranchRootWildcard, "no parent wildcard")
+			}
 			n = t.root.getChild(WildcardParamStart)
 			params.Set(n.paramKeys[0], q[1:])
 			return n
 		}
 
+		if ex != nil {
+			ex.step(q, nil, BranchNotFound, "no parent or root wildcard")
+		}
 		return nil
 	}
 
-	for i, paramValue := range paramValues {
-		if len(n.paramKeys) > i {
-			params.Set(n.paramKeys[i], paramValue)
+	setParams(n, paramValues, params)
+	return n
+}
+
+// searchFallback is called when "n" has no child for the "segment",
+// it falls back to a backtracking search if there are mid-pattern wildcards
+// and to the closest parent wildcard otherwise.
+func (t *Trie) searchFallback(n *Node, q string, params ParamsSetter, ex *Explanation, segment, reason string) *Node {
+	if t.backtracks() {
+		if found := t.searchBacktrack(q, params, ex); found != nil {
+			return found
+		}
+	}
+
+	if n = n.findClosestParentWildcardNode(); n != nil {
+		if ex != nil {
+			ex.step(segment, n, BranchClosestWildcard, reason)
+		}
+		// means that it has :param/static and *wildcard, we go trhough the :param
+		// but the next path segment is not the /static, so go back to *wildcard
+		// instead of not found.
+		//
+		// Fixes:
+		// /hello/*p
+		// /hello/:p1/static/:p2
+		// req: http://localhost:8080/hello/dsadsa/static/dsadsa => found
+		// req: http://localhost:8080/hello/dsadsa => but not found!
+		// and
+		// /second/wild/*p
+		// /second/wild/static/otherstatic/
+		// req: /second/wild/static/otherstatic/random => but not found!
+		setWildcardParams(n, q, params)
+		return n
+	}
+
+	if ex != nil {
+		ex.step(segment, nil, BranchNotFound, "no parent wildcard")
+	}
+	return nil
+}
+
+// setWildcardParams sets the parameters of the closest wildcard node "n" that matched the "q",
+// the named parameters before the wildcard, if any, are resolved by their segments,
+// i.e "/tenants/:tid/admin/*rest" for the "/tenants/acme/admin/users/42".
+func setWildcardParams(n *Node, q string, params ParamsSetter) {
+	if len(n.paramKeys) == 1 {
+		params.Set(n.paramKeys[0], q[len(n.staticKey):])
+		return
+	}
+
+	segments := slowPathSplit(n.key)
+	path := q[1:]
+	k := 0
+	for _, s := range segments[:len(segments)-1] {
+		segment := path
+		if idx := strings.IndexByte(path, pathSepB); idx != -1 {
+			segment, path = path[:idx], path[idx+1:]
+		} else {
+			path = ""
+		}
+
+		if s[0] == ParamStart[0] {
+			params.Set(n.paramKeys[k], segment)
+			k++
 		}
 	}
 
+	params.Set(n.paramKeys[k], path)
+}
+
+// backtracks reports whether the search should fall back to a backtracking one on dead ends.
+func (t *Trie) backtracks() bool {
+	return t.hasMidWildcard || t.hasRegexpParam
+}
+
+// searchBacktrack searches the whole trie with backtracking, see `matchExact`.
+func (t *Trie) searchBacktrack(q string, params ParamsSetter, ex *Explanation) *Node {
+	n, values := matchExact(t.root, q, nil)
+	if n == nil {
+		return nil
+	}
+
+	if ex != nil {
+		ex.step(q, t.root, BranchBacktrack, "mid-pattern wildcards or regexp parameters are registered")
+	}
+	setParams(n, values, params)
 	return n
 }
+
+// matchExact returns the registered node under "n" that the "q" path fully matches,
+// including the parameter values, in order, appended to the "values".
+// Unlike the `Search`, on a dead end it goes back and tries the next sibling, the precedence is:
+// static, regexp parameters (in order of registration), named parameter,
+// mid-pattern wildcard (shortest first) and then the wildcard.
+// The "q" is empty or starts with a slash.
+func matchExact(n *Node, q string, values []string) (*Node, []string) {
+	if q == "" {
+		if n.end {
+			return n, values
+		}
+		return nil, nil
+	}
+
+	q = q[1:]
+	segEnd := strings.IndexByte(q, pathSepB)
+	if segEnd == -1 {
+		segEnd = len(q)
+	}
+
+	segment := q[:segEnd]
+	if segment == "" {
+		return nil, nil
+	}
+
+	if child := n.getChild(segment); child != nil {
+		if found, v := matchExact(child, q[segEnd:], values); found != nil {
+			return found, v
+		}
+	}
+
+	for _, child := range n.regexpChildren {
+		if child.re.MatchString(segment) {
+			if found, v := matchExact(child, q[segEnd:], append(values, segment)); found != nil {
+				return found, v
+			}
+		}
+	}
+
+	if n.childNamedParameter {
+		if found, v := matchExact(n.getChild(ParamStart), q[segEnd:], append(values, segment)); found != nil {
+			return found, v
+		}
+	}
+
+	if n.childWildcardParameter {
+		wild := n.getChild(WildcardParamStart)
+		if len(wild.children) > 0 {
+			for k := segEnd; k < len(q); k++ {
+				if q[k] != pathSepB {
+					continue
+				}
+
+				if found, v := matchExact(wild, q[k:], append(values, q[:k])); found != nil {
+					return found, v
+				}
+			}
+		}
+
+		if wild.end {
+			return wild, append(values, q)
+		}
+	}
+
+	return nil, nil
+}
+
+func setParams(n *Node, values []string, params ParamsSetter) {
+	for i, paramValue := range values {
+		if len(n.paramKeys) > i {
+			params.Set(n.paramKeys[i], paramValue)
+		}
+	}
+}
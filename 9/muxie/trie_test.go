@@ -190,3 +190,160 @@ func TestTrie(t *testing.T) {
 	t.Logf("Test node one by one\n")
 	testTrie(t, true)
 }
+
+func TestTrieMidWildcard(t *testing.T) {
+	tree := NewTrie()
+	tree.InsertRoute("/repos/:owner", "owner", nil)
+	tree.InsertRoute("/repos/:owner/settings", "settings", nil)
+	tree.InsertRoute("/repos/*path/blob", "blob", nil)
+	tree.InsertRoute("/repos/*path/blob/*file", "blob_file", nil)
+	tree.InsertRoute("/repos/*path/tree/:branch", "tree", nil)
+	tree.InsertRoute("/files/*path/edit", "edit", nil)
+	tree.InsertRoute("/files/*path", "files", nil)
+	tree.InsertRoute("/files/static/edit", "static_edit", nil)
+
+	tests := []struct {
+		path   string
+		tag    string
+		params map[string]string
+	}{
+		// static and named siblings have priority.
+		{"/repos/kataras", "owner", map[string]string{"owner": "kataras"}},
+		{"/repos/kataras/settings", "settings", map[string]string{"owner": "kataras"}},
+		{"/files/static/edit", "static_edit", nil},
+		// one or more segments.
+		{"/repos/kataras/blob", "blob", map[string]string{"path": "kataras"}},
+		{"/repos/kataras/muxie/blob", "blob", map[string]string{"path": "kataras/muxie"}},
+		{"/repos/kataras/muxie/blob/master/trie.go", "blob_file", map[string]string{"path": "kataras/muxie", "file": "master/trie.go"}},
+		{"/repos/kataras/muxie/tree/master", "tree", map[string]string{"path": "kataras/muxie", "branch": "master"}},
+		{"/files/a/b/edit", "edit", map[string]string{"path": "a/b"}},
+		// shortest match wins.
+		{"/files/a/edit/b/edit", "edit", map[string]string{"path": "a/edit/b"}},
+		// falls back to the terminal wildcard.
+		{"/files/a/b", "files", map[string]string{"path": "a/b"}},
+		{"/files/edit", "files", map[string]string{"path": "edit"}},
+		// not found, the wildcard should consume at least one segment.
+		{"/files", "", nil},
+		{"/repos/kataras/muxie/other", "", nil},
+	}
+
+	for i, tt := range tests {
+		params := new(paramsWriter)
+		n := tree.Search(tt.path, params)
+		if tt.tag == "" {
+			if n != nil {
+				t.Fatalf("[%d] %s: expected to not be found but got: %s", i, tt.path, n.String())
+			}
+			continue
+		}
+
+		if n == nil {
+			t.Fatalf("[%d] %s: expected to be found:\n%s", i, tt.path, tree.Explain(tt.path))
+		}
+
+		if expected, got := tt.tag, n.Tag; expected != got {
+			t.Fatalf("[%d] %s: expected tag: '%s' but got '%s':\n%s", i, tt.path, expected, got, tree.Explain(tt.path))
+		}
+
+		if expected, got := len(tt.params), len(params.params); expected != got {
+			t.Fatalf("[%d] %s: expected %d params but got %d", i, tt.path, expected, got)
+		}
+
+		for key, value := range tt.params {
+			if expected, got := value, params.Get(key); expected != got {
+				t.Fatalf("[%d] %s: expected param '%s' to be '%s' but got '%s'", i, tt.path, key, expected, got)
+			}
+		}
+	}
+}
+
+func TestTrieRegexpParam(t *testing.T) {
+	tree := NewTrie()
+	tree.InsertRoute("/posts/:year([0-9]{4})", "year", nil)
+	tree.InsertRoute("/posts/:year([0-9]{4})/:month([0-9]{2})", "month", nil)
+	tree.InsertRoute("/posts/:year([0-9]{4})/archive", "archive", nil)
+	tree.InsertRoute("/posts/:sha([a-f0-9]{7,40})", "sha", nil)
+	tree.InsertRoute("/posts/:slug", "slug", nil)
+	tree.InsertRoute("/posts/:slug/comments", "comments", nil)
+	tree.InsertRoute("/posts/latest", "latest", nil)
+	tree.InsertRoute("/files/:name([a-z]+)", "file", nil)
+	tree.InsertRoute("/files/*path", "files", nil)
+
+	tests := []struct {
+		path   string
+		tag    string
+		params map[string]string
+	}{
+		{"/posts/latest", "latest", nil},
+		{"/posts/2018", "year", map[string]string{"year": "2018"}},
+		{"/posts/2018/10", "month", map[string]string{"year": "2018", "month": "10"}},
+		{"/posts/2018/archive", "archive", map[string]string{"year": "2018"}},
+		// the regexp has priority over the named parameter.
+		{"/posts/1234567", "sha", map[string]string{"sha": "1234567"}},
+		{"/posts/a1b2c3d", "sha", map[string]string{"sha": "a1b2c3d"}},
+		// falls back to the named parameter.
+		{"/posts/hello-world", "slug", map[string]string{"slug": "hello-world"}},
+		{"/posts/20180", "slug", map[string]string{"slug": "20180"}},
+		// backtracks to the named parameter.
+		{"/posts/2018/comments", "comments", map[string]string{"slug": "2018"}},
+		{"/files/readme", "file", map[string]string{"name": "readme"}},
+		// falls back to the wildcard.
+		{"/files/README", "files", map[string]string{"path": "README"}},
+		{"/files/readme/other", "files", map[string]string{"path": "readme/other"}},
+	}
+
+	for i, tt := range tests {
+		params := new(paramsWriter)
+		n := tree.Search(tt.path, params)
+		if n == nil {
+			t.Fatalf("[%d] %s: expected to be found:\n%s", i, tt.path, tree.Explain(tt.path))
+		}
+
+		if expected, got := tt.tag, n.Tag; expected != got {
+			t.Fatalf("[%d] %s: expected tag: '%s' but got '%s':\n%s", i, tt.path, expected, got, tree.Explain(tt.path))
+		}
+
+		if expected, got := len(tt.params), len(params.params); expected != got {
+			t.Fatalf("[%d] %s: expected %d params but got %d", i, tt.path, expected, got)
+		}
+
+		for key, value := range tt.params {
+			if expected, got := value, params.Get(key); expected != got {
+				t.Fatalf("[%d] %s: expected param '%s' to be '%s' but got '%s'", i, tt.path, key, expected, got)
+			}
+		}
+	}
+}
+
+func TestTrieRegexpParamOrder(t *testing.T) {
+	// "123" matches both of the overlapping regexp siblings, the first registered wins.
+	numFirst := NewTrie()
+	numFirst.InsertRoute("/codes/:num([0-9]+)", "num", nil)
+	numFirst.InsertRoute("/codes/:hex([0-9a-f]+)", "hex", nil)
+
+	hexFirst := NewTrie()
+	hexFirst.InsertRoute("/codes/:hex([0-9a-f]+)", "hex", nil)
+	hexFirst.InsertRoute("/codes/:num([0-9]+)", "num", nil)
+
+	tests := []struct {
+		tree *Trie
+		path string
+		tag  string
+	}{
+		{numFirst, "/codes/123", "num"},
+		{numFirst, "/codes/abc", "hex"},
+		{hexFirst, "/codes/123", "hex"},
+		{hexFirst, "/codes/abc", "hex"},
+	}
+
+	for i, tt := range tests {
+		n := tt.tree.Search(tt.path, new(paramsWriter))
+		if n == nil {
+			t.Fatalf("[%d] %s: expected to be found", i, tt.path)
+		}
+
+		if expected, got := tt.tag, n.Tag; expected != got {
+			t.Fatalf("[%d] %s: expected tag: '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// ParamType is the set of the types that a parameter of a typed route can be converted to.
+type ParamType interface {
+	~string | ~bool |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// typedRoute holds the parsed pattern of the `Route1`, `Route2` and `Route3`.
+type typedRoute struct {
+	pattern    string
+	prefix     string // the prefix of the group that the route was registered to, see `handle`.
+	registered bool
+	segments   []string
+	params     []typedParam
+}
+
+type typedParam struct {
+	segment  int // the index of the segment.
+	name     string
+	wildcard bool
+	width    int // the zero-padded width of a fixed-width digits parameter, i.e :year([0-9]{4}).
+	typ      reflect.Type
+}
+
+// newTypedRoute parses the "pattern", it panics if its parameters are not matching the "types".
+func newTypedRoute(pattern string, types ...reflect.Type) *typedRoute {
+	t := &typedRoute{pattern: pattern, segments: slowPathSplit(pattern)}
+
+	for i, s := range t.segments {
+		var p typedParam
+		switch s[0] {
+		case ParamStart[0]:
+			p = typedParam{segment: i, name: s[1:]}
+			if name, expr, ok := parseRegexpParam(s); ok {
+				p.name = name
+				p.width, _ = digitsWidth(expr)
+			}
+		case WildcardParamStart[0]:
+			p = typedParam{segment: i, name: s[1:], wildcard: true}
+		default:
+			continue
+		}
+
+		if idx := len(t.params); idx < len(types) {
+			p.typ = types[idx]
+			if p.wildcard && p.typ.Kind() != reflect.String {
+				panic(fmt.Sprintf("muxie: wildcard parameter '%s' of '%s' should be a string, not %s", p.name, pattern, p.typ))
+			}
+		}
+
+		t.params = append(t.params, p)
+	}
+
+	if expected, got := len(types), len(t.params); expected != got {
+		panic(fmt.Sprintf("muxie: pattern '%s' has %d parameters but the route expects %d", pattern, got, expected))
+	}
+
+	return t
+}
+
+// handle registers the route to the "mux", the "fn" is called with the converted values of the parameters,
+// a conversion failure is answered with 404.
+// The route is bound to the prefix of the "mux", so it panics if it's already registered.
+func (t *typedRoute) handle(mux SubMux, fn func(w http.ResponseWriter, r *http.Request, values []reflect.Value)) *Route {
+	if t.registered {
+		panic(fmt.Sprintf("muxie: route '%s' is already registered to '%s'", t.pattern, t.prefix+pathSep))
+	}
+	t.prefix, t.registered = mux.Prefix(), true
+
+	return mux.Handle(t.pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values := make([]reflect.Value, len(t.params))
+		for i, p := range t.params {
+			v, err := convertParam(GetParam(w, p.name), p.typ)
+			if err != nil {
+				Error(w, r, http.StatusNotFound)
+				return
+			}
+
+			values[i] = v
+		}
+
+		fn(w, r, values)
+	}))
+}
+
+// url builds the path of the route, including the prefix of the group it was registered to,
+// it panics if the route is not registered yet.
+func (t *typedRoute) url(values ...interface{}) string {
+	if !t.registered {
+		panic(fmt.Sprintf("muxie: route '%s' should be registered before building its URL", t.pattern))
+	}
+
+	segments := make([]string, len(t.segments))
+	copy(segments, t.segments)
+
+	for i, p := range t.params {
+		value := fmt.Sprint(values[i])
+		if p.wildcard {
+			parts := strings.Split(strings.TrimPrefix(value, pathSep), pathSep)
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			segments[p.segment] = strings.Join(parts, pathSep)
+			continue
+		}
+
+		if p.width > 1 {
+			value = padDigits(value, p.width)
+		}
+
+		segments[p.segment] = url.PathEscape(value)
+	}
+
+	return t.prefix + pathSep + strings.Join(segments, pathSep)
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Route1 is a route with one typed parameter, see `NewRoute1`.
+type Route1[T ParamType] struct {
+	route *typedRoute
+}
+
+// NewRoute1 returns a route with one typed parameter, i.e
+//
+//	users := muxie.NewRoute1[int]("/users/:id")
+//	users.Handle(mux, func(w http.ResponseWriter, r *http.Request, id int) {...})
+//	users.URL(42) // "/users/42"
+//
+// It panics if the "pattern" has not exactly one parameter.
+func NewRoute1[T ParamType](pattern string) *Route1[T] {
+	return &Route1[T]{route: newTypedRoute(pattern, typeOf[T]())}
+}
+
+// Pattern returns the pattern of the route.
+func (r *Route1[T]) Pattern() string {
+	return r.route.pattern
+}
+
+// Handle registers the route to the "mux" (or group), the returned `Route` can be used to name it, set its methods and etc.
+func (r *Route1[T]) Handle(mux SubMux, fn func(http.ResponseWriter, *http.Request, T)) *Route {
+	return r.route.handle(mux, func(w http.ResponseWriter, req *http.Request, values []reflect.Value) {
+		fn(w, req, values[0].Interface().(T))
+	})
+}
+
+// URL builds the path of the route for the "v" parameter's value.
+func (r *Route1[T]) URL(v T) string {
+	return r.route.url(v)
+}
+
+// Route2 is a route with two typed parameters, see `NewRoute2`.
+type Route2[T1, T2 ParamType] struct {
+	route *typedRoute
+}
+
+// NewRoute2 returns a route with two typed parameters, i.e
+//
+//	files := muxie.NewRoute2[int, string]("/users/:id/files/*path")
+//
+// It panics if the "pattern" has not exactly two parameters.
+func NewRoute2[T1, T2 ParamType](pattern string) *Route2[T1, T2] {
+	return &Route2[T1, T2]{route: newTypedRoute(pattern, typeOf[T1](), typeOf[T2]())}
+}
+
+// Pattern returns the pattern of the route.
+func (r *Route2[T1, T2]) Pattern() string {
+	return r.route.pattern
+}
+
+// Handle registers the route to the "mux" (or group).
+func (r *Route2[T1, T2]) Handle(mux SubMux, fn func(http.ResponseWriter, *http.Request, T1, T2)) *Route {
+	return r.route.handle(mux, func(w http.ResponseWriter, req *http.Request, values []reflect.Value) {
+		fn(w, req, values[0].Interface().(T1), values[1].Interface().(T2))
+	})
+}
+
+// URL builds the path of the route for the parameters' values.
+func (r *Route2[T1, T2]) URL(v1 T1, v2 T2) string {
+	return r.route.url(v1, v2)
+}
+
+// Route3 is a route with three typed parameters, see `NewRoute3`.
+type Route3[T1, T2, T3 ParamType] struct {
+	route *typedRoute
+}
+
+// NewRoute3 returns a route with three typed parameters.
+// It panics if the "pattern" has not exactly three parameters.
+func NewRoute3[T1, T2, T3 ParamType](pattern string) *Route3[T1, T2, T3] {
+	return &Route3[T1, T2, T3]{route: newTypedRoute(pattern, typeOf[T1](), typeOf[T2](), typeOf[T3]())}
+}
+
+// Pattern returns the pattern of the route.
+func (r *Route3[T1, T2, T3]) Pattern() string {
+	return r.route.pattern
+}
+
+// Handle registers the route to the "mux" (or group).
+func (r *Route3[T1, T2, T3]) Handle(mux SubMux, fn func(http.ResponseWriter, *http.Request, T1, T2, T3)) *Route {
+	return r.route.handle(mux, func(w http.ResponseWriter, req *http.Request, values []reflect.Value) {
+		fn(w, req, values[0].Interface().(T1), values[1].Interface().(T2), values[2].Interface().(T3))
+	})
+}
+
+// URL builds the path of the route for the parameters' values.
+func (r *Route3[T1, T2, T3]) URL(v1 T1, v2 T2, v3 T3) string {
+	return r.route.url(v1, v2, v3)
+}
@@ -0,0 +1,80 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type userID int64
+
+func TestTypedRoute(t *testing.T) {
+	mux := NewMux()
+
+	users := NewRoute1[userID]("/users/:id")
+	users.Handle(mux, func(w http.ResponseWriter, r *http.Request, id userID) {
+		fmt.Fprintf(w, "user %d", id+1)
+	}).Name("user")
+
+	files := NewRoute2[int, string]("/users/:id([0-9]+)/files/*path")
+	files.Handle(mux.Of("/v1"), func(w http.ResponseWriter, r *http.Request, id int, path string) {
+		fmt.Fprintf(w, "file %s of %d", path, id)
+	})
+
+	archive := NewRoute2[int, string]("/archive/:year([0-9]{4})/:slug")
+	archive.Handle(mux, func(w http.ResponseWriter, r *http.Request, year int, slug string) {
+		fmt.Fprintf(w, "%s of %d", slug, year)
+	})
+
+	if expected, got := "/users/42", users.URL(42); expected != got {
+		t.Fatalf("expected url: '%s' but got '%s'", expected, got)
+	}
+
+	if expected, got := "/v1/users/7/files/docs/a%20b.txt", files.URL(7, "docs/a b.txt"); expected != got {
+		t.Fatalf("expected url: '%s' but got '%s'", expected, got)
+	}
+
+	if expected, got := "/archive/0005/post", archive.URL(5, "post"); expected != got {
+		t.Fatalf("expected url: '%s' but got '%s'", expected, got)
+	}
+
+	tests := []struct {
+		path       string
+		statusCode int
+		body       string
+	}{
+		{users.URL(41), http.StatusOK, "user 42"},
+		{"/users/notanumber", http.StatusNotFound, "404 page not found\n"},
+		{files.URL(7, "docs/readme.md"), http.StatusOK, "file docs/readme.md of 7"},
+		{archive.URL(5, "post"), http.StatusOK, "post of 5"},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s: expected status code: %d but got %d", i, tt.path, expected, got)
+		}
+
+		if expected, got := tt.body, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+
+	expectPanic := func(name string, fn func()) {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("%s: expected a panic", name)
+			}
+		}()
+		fn()
+	}
+
+	expectPanic("missing parameter", func() { NewRoute2[int, int]("/users/:id") })
+	expectPanic("too many parameters", func() { NewRoute1[int]("/users/:id/:name") })
+	expectPanic("typed wildcard", func() { NewRoute1[int]("/files/*path") })
+	expectPanic("registered twice", func() { users.Handle(mux.Of("/v2"), func(http.ResponseWriter, *http.Request, userID) {}) })
+	expectPanic("url before handle", func() { NewRoute1[int]("/posts/:id").URL(1) })
+}
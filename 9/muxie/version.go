@@ -0,0 +1,208 @@
+package muxie
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Versioning holds the API versioning settings of a `Mux` or a group of it,
+// see `Mux#HandleVersion` and `Mux#Versioning`.
+//
+// The requested version is read from the `Header` and, if missing, from the "Accept" header,
+// i.e "application/vnd.acme.v2+json" or "application/json; version=2".
+// A requested version matches a registered one when it's equal or a prefix of it,
+// i.e "2" matches the latest of "2.0" and "2.1". The "latest" version can be requested too.
+type Versioning struct {
+	// Header is the request header that holds the version, defaults to "X-API-Version".
+	Header string
+	// Vendor is the vendor of the "application/vnd.{vendor}.v{version}+json" media types,
+	// if empty then any vendor is accepted.
+	Vendor string
+	// Default is the version to serve when no version is requested,
+	// if empty then the latest registered version is served.
+	Default string
+	// NotMatchStatus is the status code to respond with when the requested version is not registered
+	// and the route has no handler without a version, defaults to 406 Not Acceptable, it can be 400 Bad Request.
+	NotMatchStatus int
+}
+
+// DefaultVersioning is the versioning settings which are used when `Mux#Versioning` is nil.
+var DefaultVersioning = &Versioning{
+	Header:         "X-API-Version",
+	NotMatchStatus: http.StatusNotAcceptable,
+}
+
+// LatestVersion can be requested in order to serve the latest registered version.
+const LatestVersion = "latest"
+
+type versionedHandler struct {
+	version string
+	parsed  []int
+	handler http.Handler
+}
+
+// WithVersion registers a "handler" to serve the "version", i.e "2" or "2.1", of a route.
+// A node can hold many of them, see `Versioning`.
+func WithVersion(version string, handler http.Handler) InsertOption {
+	parsed, ok := parseVersion(version)
+	if !ok {
+		panic("muxie: invalid version: " + version)
+	}
+
+	return func(n *Node) {
+		for _, v := range n.versions {
+			if v.version == version {
+				v.handler = handler
+				return
+			}
+		}
+
+		n.versions = append(n.versions, &versionedHandler{version: version, parsed: parsed, handler: handler})
+		sort.Slice(n.versions, func(i, j int) bool {
+			return compareVersions(n.versions[i].parsed, n.versions[j].parsed) < 0
+		})
+	}
+}
+
+// HandleVersion registers a "handler" to serve the "version" of the "pattern",
+// same pattern can hold many versions, the one to serve is negotiated through the `Versioning` settings.
+func (m *Mux) HandleVersion(pattern, version string, handler http.Handler) {
+	m.Routes.insertNode(m.root+pattern, WithVersion(version, handler), withMux(m))
+}
+
+// GetVersion returns the version of the route that serves the request, if any.
+func GetVersion(w http.ResponseWriter) string {
+	if store, ok := w.(*paramsWriter); ok {
+		return store.version
+	}
+
+	return ""
+}
+
+// parseVersion parses a version, i.e "2", "v2.1" to []int{2, 1}.
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	if version == "" {
+		return nil, false
+	}
+
+	parts := strings.Split(version, ".")
+	parsed := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parsed[i] = n
+	}
+
+	return parsed, true
+}
+
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return len(a) - len(b)
+}
+
+var vendorMediaTypeRegexp = regexp.MustCompile(`^application/vnd\.([^+;]+)\.v([0-9][0-9.]*)(\+[a-z]+)?$`)
+
+// requestedVersion returns the version that the request asks for, if any.
+func (v *Versioning) requestedVersion(r *http.Request) string {
+	header := v.Header
+	if header == "" {
+		header = DefaultVersioning.Header
+	}
+
+	if version := r.Header.Get(header); version != "" {
+		return strings.TrimSpace(version)
+	}
+
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			parts := strings.Split(mediaType, ";")
+			typ := strings.ToLower(strings.TrimSpace(parts[0]))
+			if matches := vendorMediaTypeRegexp.FindStringSubmatch(typ); matches != nil {
+				if v.Vendor == "" || v.Vendor == matches[1] {
+					return matches[2]
+				}
+			}
+
+			for _, param := range parts[1:] {
+				if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(key, "version") {
+					return strings.Trim(value, `"`)
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// negotiate returns the registered version of "n" that the request asks for.
+func (v *Versioning) negotiate(n *Node, r *http.Request) *versionedHandler {
+	requested := v.requestedVersion(r)
+	if requested == "" {
+		requested = v.Default
+	}
+
+	if requested == "" || requested == LatestVersion {
+		return n.versions[len(n.versions)-1]
+	}
+
+	parsed, ok := parseVersion(requested)
+	if !ok {
+		return nil
+	}
+
+	// the latest version which starts with the requested one.
+	for i := len(n.versions) - 1; i >= 0; i-- {
+		candidate := n.versions[i]
+		if len(candidate.parsed) >= len(parsed) && compareVersions(candidate.parsed[:len(parsed)], parsed) == 0 {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+func (v *Versioning) notMatchStatus() int {
+	if v.NotMatchStatus == 0 {
+		return DefaultVersioning.NotMatchStatus
+	}
+
+	return v.NotMatchStatus
+}
+
+// handlerFor returns the handler of "n" that should serve the request,
+// the one of the negotiated version, of the passing matchers or the `Handler`, in that order.
+func (m *Mux) handlerFor(n *Node, pw *paramsWriter, r *http.Request) http.Handler {
+	if len(n.versions) == 0 {
+		return n.handlerFor(r)
+	}
+
+	versioning := m.versioning()
+	if v := versioning.negotiate(n, r); v != nil {
+		pw.version = v.version
+		return v.handler
+	}
+
+	if h := n.handlerFor(r); h != nil {
+		return h
+	}
+
+	status := versioning.notMatchStatus()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Error(w, r, status)
+	})
+}
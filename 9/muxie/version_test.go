@@ -0,0 +1,76 @@
+package muxie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMuxHandleVersion(t *testing.T) {
+	writeVersion := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s:%s:%s", name, GetVersion(w), GetParam(w, "id"))
+		}
+	}
+
+	mux := NewMux()
+	mux.HandleVersion("/users/:id", "1", writeVersion("v1"))
+	mux.HandleVersion("/users/:id", "2.0", writeVersion("v2.0"))
+	mux.HandleVersion("/users/:id", "2.1", writeVersion("v2.1"))
+
+	api := mux.Of("/api")
+	api.SetVersioning(&Versioning{
+		Header:         "X-Version",
+		Vendor:         "acme",
+		Default:        "1",
+		NotMatchStatus: http.StatusBadRequest,
+	})
+	api.HandleVersion("/items", "1", writeVersion("items1"))
+	api.HandleVersion("/items", "2", writeVersion("items2"))
+
+	mux.HandleVersion("/fallback", "1", writeVersion("fallback1"))
+	mux.HandleFunc("/fallback", writeVersion("fallback"))
+
+	tests := []struct {
+		path       string
+		header     http.Header
+		statusCode int
+		expected   string
+	}{
+		// latest by default.
+		{"/users/42", nil, http.StatusOK, "v2.1:2.1:42"},
+		{"/users/42", http.Header{"X-Api-Version": {"1"}}, http.StatusOK, "v1:1:42"},
+		{"/users/42", http.Header{"X-Api-Version": {"2"}}, http.StatusOK, "v2.1:2.1:42"},
+		{"/users/42", http.Header{"X-Api-Version": {"2.0"}}, http.StatusOK, "v2.0:2.0:42"},
+		{"/users/42", http.Header{"X-Api-Version": {"latest"}}, http.StatusOK, "v2.1:2.1:42"},
+		{"/users/42", http.Header{"Accept": {"application/vnd.any.v1+json"}}, http.StatusOK, "v1:1:42"},
+		{"/users/42", http.Header{"Accept": {"text/html, application/json; version=2.0"}}, http.StatusOK, "v2.0:2.0:42"},
+		{"/users/42", http.Header{"X-Api-Version": {"3"}}, http.StatusNotAcceptable, "Not Acceptable\n"},
+		{"/users/42", http.Header{"X-Api-Version": {"invalid"}}, http.StatusNotAcceptable, "Not Acceptable\n"},
+		// group settings.
+		{"/api/items", nil, http.StatusOK, "items1:1:"},
+		{"/api/items", http.Header{"X-Version": {"2"}}, http.StatusOK, "items2:2:"},
+		{"/api/items", http.Header{"Accept": {"application/vnd.acme.v2+json"}}, http.StatusOK, "items2:2:"},
+		{"/api/items", http.Header{"Accept": {"application/vnd.other.v2+json"}}, http.StatusOK, "items1:1:"},
+		{"/api/items", http.Header{"X-Version": {"3"}}, http.StatusBadRequest, "Bad Request\n"},
+		// handler without a version.
+		{"/fallback", http.Header{"X-Api-Version": {"1"}}, http.StatusOK, "fallback1:1:"},
+		{"/fallback", http.Header{"X-Api-Version": {"2"}}, http.StatusOK, "fallback::"},
+	}
+
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header = tt.header
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if expected, got := tt.statusCode, rec.Code; expected != got {
+			t.Fatalf("[%d] %s: expected status code: %d but got %d", i, tt.path, expected, got)
+		}
+
+		if expected, got := tt.expected, rec.Body.String(); expected != got {
+			t.Fatalf("[%d] %s: expected to receive '%s' but got '%s'", i, tt.path, expected, got)
+		}
+	}
+}